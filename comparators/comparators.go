@@ -1,6 +1,8 @@
 package comparators
 
 import (
+	"math"
+
 	"pixorder/shared"
 	"pixorder/types"
 )
@@ -16,6 +18,29 @@ var ComparatorFunctionMappings = map[string]types.ComparatorFunc{
 	"min":        Min,
 }
 
+// what each comparator compares, for a single pixel. unlike the comparators
+// these dont skip masked or thresholded pixels
+var ValueFunctionMappings = map[string]func(pixel types.PixelWithMask) float32{
+	"red": func(pixel types.PixelWithMask) float32 {
+		return float32(pixel.R)
+	},
+	"green": func(pixel types.PixelWithMask) float32 {
+		return float32(pixel.G)
+	},
+	"blue": func(pixel types.PixelWithMask) float32 {
+		return float32(pixel.B)
+	},
+	"hue":        calculateHue,
+	"saturation": calculateSaturation,
+	"lightness":  calculateLightness,
+	"max": func(pixel types.PixelWithMask) float32 {
+		return float32(max(pixel.R, pixel.G, pixel.B))
+	},
+	"min": func(pixel types.PixelWithMask) float32 {
+		return float32(min(pixel.R, pixel.G, pixel.B))
+	},
+}
+
 func Red(a, b types.PixelWithMask) int {
 	if skipPixel(a) || skipPixel(b) {
 		return 0
@@ -101,11 +126,13 @@ func skipPixel(pixel types.PixelWithMask) bool {
 	}
 	/// and if beyond thresholds
 	/// FIXME: figure out why thresholds with spiral results in holes in the image
+	return !InThresholds(pixel)
+}
+
+// whether the pixel's lightness is within shared.Config.Thresholds
+func InThresholds(pixel types.PixelWithMask) bool {
 	lightness := calculateLightness(pixel)
-	if lightness < shared.Config.Thresholds.Lower*255 || lightness > shared.Config.Thresholds.Upper*255 {
-		return true
-	}
-	return false
+	return lightness >= shared.Config.Thresholds.Lower*255 && lightness <= shared.Config.Thresholds.Upper*255
 }
 
 func calculateLightness(pixel types.PixelWithMask) float32 {
@@ -160,3 +187,43 @@ func calculateSaturation(pixel types.PixelWithMask) float32 {
 	}
 	return saturation * 1000
 }
+
+// straight-line distance between two colors in rgb space, 0-441
+func RGBDistance(a, b types.PixelWithMask) float64 {
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
+
+// CIE76 ΔE, distance in L*a*b* space. ~2.3 is barely noticeable, 100 is black vs white
+func DeltaE(a, b types.PixelWithMask) float64 {
+	aL, aA, aB := calculateLab(a)
+	bL, bA, bB := calculateLab(b)
+	return math.Sqrt((aL-bL)*(aL-bL) + (aA-bA)*(aA-bA) + (aB-bB)*(aB-bB))
+}
+
+// srgb -> linear -> xyz (d65) -> L*a*b*
+func calculateLab(pixel types.PixelWithMask) (float64, float64, float64) {
+	linear := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(pixel.R), linear(pixel.G), linear(pixel.B)
+	/// normalized to the d65 white point
+	x := (r*0.4124 + g*0.3576 + b*0.1805) / 0.95047
+	y := r*0.2126 + g*0.7152 + b*0.0722
+	z := (r*0.0193 + g*0.1192 + b*0.9505) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
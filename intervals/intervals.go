@@ -1,8 +1,9 @@
 package intervals
 
 import (
+	"hash/fnv"
 	"math"
-	mathRand "math/rand"
+	mathRand "math/rand/v2"
 	"slices"
 
 	"pixorder/comparators"
@@ -17,29 +18,76 @@ import (
 /// i should get this straightened out
 
 // interval sorting algos
-var IntervalFunctionMappings = map[string]func([]types.PixelWithMask){
-	"none":    None,
-	"random":  Random,
-	"shuffle": Shuffle,
-	"smear":   Smear,
-	"wave":    Wave,
+var IntervalFunctionMappings = map[string]func([]types.PixelWithMask, *State){
+	"none":      None,
+	"random":    Random,
+	"shuffle":   Shuffle,
+	"smear":     Smear,
+	"wave":      Wave,
+	"threshold": Threshold,
+	"edges":     Edges,
+	"file":      File,
+	"distance":  Distance,
+	"sine":      Sine,
+	"triangle":  Triangle,
+	"sawtooth":  Sawtooth,
+	"partial":   Partial,
+	"glitch":    Glitch,
+	"softmask":  SoftMask,
+}
+
+// what an interval knows about the seam its sorting
+type State struct {
+	// which seam this is
+	Seam int
+	// where the current stretch starts in the seam, Sort hands over one unmasked stretch at a time
+	Offset int
+	// seeded per seam, see SeamSeed
+	Rand *mathRand.Rand
+	// one value per image pixel for the intervals that split on something
+	// other than the pixels themselves, like the edges interval's edge map
+	Guide []uint8
+	// where each pixel of the seam sits in the image, to look up Guide.
+	// -1 for nowhere, see patterns.SeamPaths
+	Path []int
+
+	seed   int64
+	source *mathRand.PCG
+}
+
+// state for sorting one image's seams, imageSeed is ImageSeed(shared.Config.Seed, name)
+func NewState(imageSeed int64) *State {
+	source := mathRand.NewPCG(0, 0)
+	return &State{Rand: mathRand.New(source), seed: imageSeed, source: source}
+}
+
+// moves on to seam i and reseeds Rand for it, so every seam sorts the same no
+// matter what order seams (or images) get sorted in. reseeding pcg is cheap
+// enough to do for every seam
+func (state *State) SetSeam(i int) {
+	state.Seam = i
+	if state.source != nil {
+		state.source.Seed(uint64(state.seed), uint64(SeamSeed(state.seed, i)))
+	}
 }
 
 // sorters
 
-func Sort(seam []types.PixelWithMask) {
+// call state.SetSeam furst so the seam gets its own state.Rand
+func Sort(seam []types.PixelWithMask, state *State) {
 	sorter := IntervalFunctionMappings[shared.Config.Interval]
 	stretches := getUnmaskedStretches(seam)
 	for i := 0; i < len(stretches); i++ {
 		stretch := stretches[i]
-		sorter(seam[stretch.Start:stretch.End])
+		state.Offset = stretch.Start
+		sorter(seam[stretch.Start:stretch.End], state)
 	}
 }
-func Shuffle(seam []types.PixelWithMask) {
+func Shuffle(seam []types.PixelWithMask, state *State) {
 	/// we want shuffling to respect thresholds/masks too, so
 	/// use the result to determine whether to skip or not
 	comparator := comparators.ComparatorFunctionMappings[shared.Config.Comparator]
-	mathRand.Shuffle(len(seam), func(i, j int) {
+	state.Rand.Shuffle(len(seam), func(i, j int) {
 		skip := comparator(seam[i], seam[j])
 		if skip != 0 {
 			seam[i], seam[j] = seam[j], seam[i]
@@ -48,7 +96,7 @@ func Shuffle(seam []types.PixelWithMask) {
 }
 
 // smear pixels across the rest of the seam
-func Smear(seam []types.PixelWithMask) {
+func Smear(seam []types.PixelWithMask, state *State) {
 	intervalLength := len(seam)
 	if intervalLength == 0 {
 		return
@@ -66,12 +114,12 @@ func Smear(seam []types.PixelWithMask) {
 }
 
 // noop, returns a single stretch containing the full seam
-func None(seam []types.PixelWithMask) {
+func None(seam []types.PixelWithMask, state *State) {
 	commonSort([]types.PixelStretch{{Start: 0, End: len(seam)}}, seam)
 }
 
 // takes a randomly-sized chunk of the remaining pixels and sorts them
-func Random(seam []types.PixelWithMask) {
+func Random(seam []types.PixelWithMask, state *State) {
 	stretches := make([]types.PixelStretch, 0)
 	intervalLength := len(seam)
 
@@ -80,8 +128,8 @@ func Random(seam []types.PixelWithMask) {
 		if j >= intervalLength {
 			break
 		}
-		randLength := randBetween((intervalLength - j), 1)
-		if mathRand.Float32() < shared.Config.Randomness {
+		randLength := randBetween(state.Rand, (intervalLength - j), 1)
+		if state.Rand.Float32() < shared.Config.Randomness {
 			endIdx := min(j+randLength, intervalLength)
 			stretches = append(stretches, types.PixelStretch{Start: j, End: endIdx})
 		}
@@ -93,7 +141,7 @@ func Random(seam []types.PixelWithMask) {
 
 // sorts in "waves" across the interval
 // not very useful with complex masks
-func Wave(seam []types.PixelWithMask) {
+func Wave(seam []types.PixelWithMask, state *State) {
 	stretches := make([]types.PixelStretch, 0)
 	intervalLength := len(seam)
 	baseLength := shared.Config.SectionLength
@@ -108,7 +156,7 @@ func Wave(seam []types.PixelWithMask) {
 		waveOffsetMin := math.Floor(float64(float32(baseLength) * shared.Config.Randomness))
 
 		/// waves can reach forward or hang back
-		waveLength := baseLength + randBetween(int(waveOffsetMin), int(-waveOffsetMin))
+		waveLength := baseLength + randBetween(state.Rand, int(waveOffsetMin), int(-waveOffsetMin))
 
 		/// now add to stretches
 		endIdx := min(j+waveLength, intervalLength)
@@ -118,17 +166,250 @@ func Wave(seam []types.PixelWithMask) {
 	commonSort(stretches, seam)
 }
 
+// sorts each run of pixels within the lightness thresholds, like satyarth/pixelsort's "threshold"
+// pixels outside of them split the seam and stay put
+func Threshold(seam []types.PixelWithMask, state *State) {
+	stretches := make([]types.PixelStretch, 0)
+	start := -1
+	for i, pixel := range seam {
+		if comparators.InThresholds(pixel) {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			stretches = append(stretches, types.PixelStretch{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		stretches = append(stretches, types.PixelStretch{Start: start, End: len(seam)})
+	}
+	commonSort(stretches, seam)
+}
+
+// sorts between edges, pixels where the guide (patterns.EdgeMagnitude) is above
+// shared.Config.EdgeThreshold, like satyarth/pixelsort's "edges"
+func Edges(seam []types.PixelWithMask, state *State) {
+	edge := uint8(shared.Config.EdgeThreshold * 255)
+	stretches := make([]types.PixelStretch, 0)
+	start := 0
+	for i := range seam {
+		if state.guideAt(i) > edge {
+			/// edges split the seam and stay put
+			if i > start {
+				stretches = append(stretches, types.PixelStretch{Start: start, End: i})
+			}
+			start = i + 1
+		}
+	}
+	if start < len(seam) {
+		stretches = append(stretches, types.PixelStretch{Start: start, End: len(seam)})
+	}
+	commonSort(stretches, seam)
+}
+
+// starts a new stretch wherever the interval image (the guide) flips between
+// black and white, so intervals can be painted by hand
+func File(seam []types.PixelWithMask, state *State) {
+	stretches := make([]types.PixelStretch, 0)
+	start := 0
+	for i := 1; i < len(seam); i++ {
+		/// treat it as b&w so jpeg noise doesnt split everything
+		if (state.guideAt(i) >= 128) != (state.guideAt(i-1) >= 128) {
+			stretches = append(stretches, types.PixelStretch{Start: start, End: i})
+			start = i
+		}
+	}
+	stretches = append(stretches, types.PixelStretch{Start: start, End: len(seam)})
+	commonSort(stretches, seam)
+}
+
+// starts a new stretch wherever neighboring pixels are more than shared.Config.ColorDistance
+// apart, so sharp color changes dont get smeared across
+func Distance(seam []types.PixelWithMask, state *State) {
+	distance := comparators.RGBDistance
+	if shared.Config.DistanceMetric == "deltae" {
+		distance = comparators.DeltaE
+	}
+	stretches := make([]types.PixelStretch, 0)
+	start := 0
+	for i := 1; i < len(seam); i++ {
+		if distance(seam[i-1], seam[i]) > shared.Config.ColorDistance {
+			stretches = append(stretches, types.PixelStretch{Start: start, End: i})
+			start = i
+		}
+	}
+	stretches = append(stretches, types.PixelStretch{Start: start, End: len(seam)})
+	commonSort(stretches, seam)
+}
+
+// lets gray in the mask fade the sort out instead of cutting it off: the darker the mask
+// where a stretch starts, the longer it is (up to shared.Config.SectionLength) and
+// the likelier it gets sorted. black sorts like normal, white is still skipped
+func SoftMask(seam []types.PixelWithMask, state *State) {
+	stretches := make([]types.PixelStretch, 0)
+	intervalLength := len(seam)
+
+	j := 0
+	for j < intervalLength {
+		strength := 1 - float64(seam[j].Mask)/255
+		length := max(int(math.Round(float64(shared.Config.SectionLength)*strength)), 1)
+
+		endIdx := min(j+length, intervalLength)
+		if state.Rand.Float64() < strength {
+			stretches = append(stretches, types.PixelStretch{Start: j, End: endIdx})
+		}
+		j += length
+	}
+	commonSort(stretches, seam)
+}
+
+// doesnt sort, chops the seam into blocks around shared.Config.SectionLength long and
+// displaces shared.Config.Randomness of them: swapped with the next block, or slid
+// along inside themselves. datamosh-y
+func Glitch(seam []types.PixelWithMask, state *State) {
+	blocks := make([]types.PixelStretch, 0)
+	intervalLength := len(seam)
+	baseLength := shared.Config.SectionLength
+
+	j := 0
+	for j < intervalLength {
+		/// anywhere from half to one and a half the base length
+		blockLength := randBetween(state.Rand, baseLength*3/2, max(baseLength/2, 1))
+		endIdx := min(j+blockLength, intervalLength)
+		blocks = append(blocks, types.PixelStretch{Start: j, End: endIdx})
+		j += blockLength
+	}
+
+	for i := 0; i < len(blocks); i++ {
+		block := blocks[i]
+		if state.Rand.Float32() >= shared.Config.Randomness {
+			continue
+		}
+		if i+1 < len(blocks) && state.Rand.IntN(2) == 0 {
+			/// swapping neighbors is just rotating them both
+			rotate(seam[block.Start:blocks[i+1].End], block.End-block.Start)
+			/// the next block already moved
+			i++
+		} else if blockLength := block.End - block.Start; blockLength > 1 {
+			rotate(seam[block.Start:block.End], randBetween(state.Rand, blockLength-1, 1))
+		}
+	}
+}
+
+// only runs shared.Config.Passes rounds of odd-even transposition sort, so pixels
+// drift toward where they'd end up without getting there. "half-melted"
+//
+// bump passes frame by frame to animate the sort
+func Partial(seam []types.PixelWithMask, state *State) {
+	comparator := comparators.ComparatorFunctionMappings[shared.Config.Comparator]
+	order := 1
+	if shared.Config.Reverse {
+		order = -1
+	}
+	/// two quiet passes in a row (one odd, one even) means its sorted
+	quiet := 0
+	for pass := 0; pass < shared.Config.Passes && quiet < 2; pass++ {
+		quiet++
+		for i := pass % 2; i+1 < len(seam); i += 2 {
+			if comparator(seam[i], seam[i+1])*order > 0 {
+				seam[i], seam[i+1] = seam[i+1], seam[i]
+				quiet = 0
+			}
+		}
+	}
+}
+
+// stretch lengths swing along the seam like a sine wave, and each seam's wave is
+// shifted a little from the last so the boundaries line up into diagonals
+func Sine(seam []types.PixelWithMask, state *State) {
+	periodic(seam, state, func(t float64) float64 {
+		return math.Sin(2 * math.Pi * t)
+	})
+}
+
+// sine, but with straight ramps
+func Triangle(seam []types.PixelWithMask, state *State) {
+	periodic(seam, state, func(t float64) float64 {
+		return 1 - 4*math.Abs(t-math.Floor(t)-0.5)
+	})
+}
+
+// sine, but stretches grow then snap back
+func Sawtooth(seam []types.PixelWithMask, state *State) {
+	periodic(seam, state, func(t float64) float64 {
+		return 2*(t-math.Floor(t)) - 1
+	})
+}
+
+// wave takes the position in periods and gives back -1 to 1
+func periodic(seam []types.PixelWithMask, state *State, wave func(t float64) float64) {
+	stretches := make([]types.PixelStretch, 0)
+	intervalLength := len(seam)
+	baseLength := float64(shared.Config.SectionLength)
+	period := shared.Config.Periodic.Period
+	/// where this seam's wave starts, in pixels
+	shift := float64(state.Seam)*shared.Config.Periodic.Phase + float64(state.Offset)
+
+	j := 0
+	for j < intervalLength {
+		t := (shift + float64(j)) / period
+		length := int(math.Round(baseLength * (1 + shared.Config.Periodic.Amplitude*wave(t))))
+		length = max(length, 1)
+
+		endIdx := min(j+length, intervalLength)
+		stretches = append(stretches, types.PixelStretch{Start: j, End: endIdx})
+		j += length
+	}
+	commonSort(stretches, seam)
+}
+
 ///
 
 /// util
 
+// the guide under pixel i of the stretch being sorted
+func (state *State) guideAt(i int) uint8 {
+	index := state.Path[state.Offset+i]
+	if index < 0 {
+		return 0
+	}
+	return state.Guide[index]
+}
+
+// one image's seed, from shared.Config.Seed and the image's file name (not its
+// path or place in the inputs), so the image sorts the same however it's run
+func ImageSeed(seed int64, name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return SeamSeed(seed^int64(hash.Sum64()), 0)
+}
+
+// mixes n into seed (splitmix64), so every seam gets its own well-spread seed
+// out of the image's
+//
+// seam seed: SeamSeed(imageSeed, seamIdx)
+func SeamSeed(seed int64, n int) int64 {
+	z := uint64(seed) + uint64(n+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// shifts pixels k to the left, wrapping around
+func rotate(pixels []types.PixelWithMask, k int) {
+	slices.Reverse(pixels[:k])
+	slices.Reverse(pixels[k:])
+	slices.Reverse(pixels)
+}
+
 // inclusive
-func randBetween(max int, min_opt ...int) int {
+func randBetween(rng *mathRand.Rand, max int, min_opt ...int) int {
 	min := 0
 	if len(min_opt) > 0 {
 		min = min_opt[0]
 	}
-	randNum := mathRand.Float64()
+	randNum := rng.Float64()
 	if min != 0 {
 		return int(math.Floor(randNum*float64(((+max)+1)-(+min)))) + (+min)
 	}
@@ -0,0 +1,321 @@
+package intervals_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"pixorder/intervals"
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+func TestThreshold(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0.2, Upper: 0.8}
+	})
+
+	// Two runs inside the thresholds, split by a pixel that's too bright
+	seam := []types.PixelWithMask{
+		gray(150), gray(100), gray(250), gray(120), gray(80), gray(10),
+	}
+	intervals.Threshold(seam, nil)
+	expected := []types.PixelWithMask{
+		gray(100), gray(150), gray(250), gray(80), gray(120), gray(10),
+	}
+	compareSeams(expected, seam, t)
+}
+
+func TestEdges(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.EdgeThreshold = 0.5
+	})
+
+	// The edge in the middle stays put and splits the sort
+	seam := []types.PixelWithMask{gray(90), gray(30), gray(200), gray(60), gray(10)}
+	state := &intervals.State{Guide: []uint8{0, 0, 255, 0, 0}, Path: []int{0, 1, 2, 3, 4}}
+	intervals.Edges(seam, state)
+	expected := []types.PixelWithMask{gray(30), gray(90), gray(200), gray(10), gray(60)}
+	compareSeams(expected, seam, t)
+}
+
+func TestFile(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+	})
+
+	// Black, black, white, white (close enough), black, looked up along a
+	// seam that runs backwards through the image
+	seam := []types.PixelWithMask{gray(90), gray(30), gray(200), gray(60), gray(10)}
+	state := &intervals.State{Guide: []uint8{10, 200, 255, 20, 0}, Path: []int{4, 3, 2, 1, 0}}
+	intervals.File(seam, state)
+	expected := []types.PixelWithMask{gray(30), gray(90), gray(60), gray(200), gray(10)}
+	compareSeams(expected, seam, t)
+}
+
+func TestDistance(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.ColorDistance = 50
+	})
+
+	// Dark pair, jump, bright pair: each pair sorts on its own
+	for _, metric := range []string{"rgb", "deltae"} {
+		shared.Config.DistanceMetric = metric
+		seam := []types.PixelWithMask{gray(40), gray(20), gray(220), gray(200)}
+		intervals.Distance(seam, nil)
+		expected := []types.PixelWithMask{gray(20), gray(40), gray(200), gray(220)}
+		compareSeams(expected, seam, t)
+	}
+}
+
+func TestSeeded(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.Randomness = 0.5
+		shared.Config.SectionLength = 5
+	})
+
+	source := make([]types.PixelWithMask, 200)
+	for i := range source {
+		source[i] = gray(uint8(i * 97))
+	}
+	sortWith := func(interval string, state *intervals.State, seamIdx int) []types.PixelWithMask {
+		shared.Config.Interval = interval
+		seam := slices.Clone(source)
+		state.SetSeam(seamIdx)
+		intervals.Sort(seam, state)
+		return seam
+	}
+
+	for _, interval := range []string{"random", "shuffle", "wave", "glitch"} {
+		// Seams sort the same whatever order they come in
+		forwards, backwards := intervals.NewState(42), intervals.NewState(42)
+		results := make([][]types.PixelWithMask, 4)
+		for i := range results {
+			results[i] = sortWith(interval, forwards, i)
+		}
+		for i := len(results) - 1; i >= 0; i-- {
+			compareSeams(results[i], sortWith(interval, backwards, i), t)
+		}
+		if slices.Equal(results[0], results[1]) {
+			t.Errorf("%s: different seams got the same randomness", interval)
+		}
+		if slices.Equal(results[0], sortWith(interval, intervals.NewState(43), 0)) {
+			t.Errorf("%s: different seeds gave the same result", interval)
+		}
+	}
+
+	// Neighboring images and seams shouldn't share seeds
+	seen := map[int64]bool{}
+	for image := 0; image < 10; image++ {
+		imageSeed := intervals.ImageSeed(1, fmt.Sprintf("frame%03d.png", image))
+		for seam := 0; seam < 100; seam++ {
+			seed := intervals.SeamSeed(imageSeed, seam)
+			if seen[seed] {
+				t.Fatalf("seed for image %d seam %d was already used", image, seam)
+			}
+			seen[seed] = true
+		}
+	}
+	// An image's seed only depends on the seed and its name
+	if intervals.ImageSeed(1, "frame001.png") != intervals.ImageSeed(1, "frame001.png") {
+		t.Errorf("the same image got different seeds")
+	}
+	if intervals.ImageSeed(1, "frame001.png") == intervals.ImageSeed(2, "frame001.png") {
+		t.Errorf("different seeds gave an image the same seed")
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.SectionLength = 4
+		shared.Config.Periodic = types.PeriodicConfig{Amplitude: 0.5, Period: 16, Phase: 2}
+	})
+
+	// Descending seams, so each stretch comes out as its own ascending run
+	boundaries := func(interval func([]types.PixelWithMask, *intervals.State), seamIdx int) []int {
+		seam := make([]types.PixelWithMask, 24)
+		for i := range seam {
+			seam[i] = gray(uint8(240 - i*10))
+		}
+		interval(seam, &intervals.State{Seam: seamIdx})
+		starts := []int{0}
+		for i := 1; i < len(seam); i++ {
+			if seam[i].R < seam[i-1].R {
+				starts = append(starts, i)
+			}
+		}
+		return starts
+	}
+
+	// Lengths 4*(1+0.5*wave) at each stretch start
+	tests := []struct {
+		name     string
+		interval func([]types.PixelWithMask, *intervals.State)
+		seam     int
+		expected []int
+	}{
+		{"sine", intervals.Sine, 0, []int{0, 4, 10, 13, 15, 18, 23}},
+		{"sine shifted", intervals.Sine, 1, []int{0, 5, 10, 12, 15, 20}},
+		{"triangle", intervals.Triangle, 0, []int{0, 2, 5, 10, 15, 18, 21}},
+		{"sawtooth", intervals.Sawtooth, 0, []int{0, 2, 5, 8, 12, 17, 19, 22}},
+	}
+	for _, test := range tests {
+		starts := boundaries(test.interval, test.seam)
+		if !slices.Equal(starts, test.expected) {
+			t.Errorf("%s: stretches start at %v, expected %v", test.name, starts, test.expected)
+		}
+	}
+}
+
+func TestPartial(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+	})
+	grays := func(values ...uint8) []types.PixelWithMask {
+		seam := make([]types.PixelWithMask, len(values))
+		for i, v := range values {
+			seam[i] = gray(v)
+		}
+		return seam
+	}
+
+	tests := []struct {
+		name     string
+		passes   int
+		reverse  bool
+		seam     []types.PixelWithMask
+		expected []types.PixelWithMask
+	}{
+		{"one pass", 1, false, grays(50, 40, 30, 20, 10), grays(40, 50, 20, 30, 10)},
+		{"two passes", 2, false, grays(50, 40, 30, 20, 10), grays(40, 20, 50, 10, 30)},
+		{"enough passes", 100, false, grays(50, 40, 30, 20, 10), grays(10, 20, 30, 40, 50)},
+		{"reversed", 1, true, grays(10, 20, 30, 40, 50), grays(20, 10, 40, 30, 50)},
+	}
+	for _, test := range tests {
+		shared.Config.Passes = test.passes
+		shared.Config.Reverse = test.reverse
+		intervals.Partial(test.seam, nil)
+		compareSeams(test.expected, test.seam, t)
+	}
+}
+
+func TestGlitch(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Interval = "glitch"
+		shared.Config.SectionLength = 6
+	})
+
+	// The middle third is masked off and has to stay put
+	source := make([]types.PixelWithMask, 60)
+	for i := range source {
+		source[i] = gray(uint8(i + 1))
+		if i >= 20 && i < 40 {
+			source[i].Mask = 255
+		}
+	}
+	glitch := func(randomness float32) []types.PixelWithMask {
+		shared.Config.Randomness = randomness
+		seam := slices.Clone(source)
+		state := intervals.NewState(1)
+		state.SetSeam(0)
+		intervals.Sort(seam, state)
+		return seam
+	}
+
+	compareSeams(source, glitch(0), t)
+
+	seam := glitch(1)
+	if slices.Equal(seam, source) {
+		t.Errorf("nothing got displaced")
+	}
+	compareSeams(source[20:40], seam[20:40], t)
+	// Only moved around inside the unmasked thirds
+	for _, third := range [][2]int{{0, 20}, {40, 60}} {
+		moved := slices.Clone(seam[third[0]:third[1]])
+		slices.SortFunc(moved, func(a, b types.PixelWithMask) int {
+			return int(a.R) - int(b.R)
+		})
+		compareSeams(source[third[0]:third[1]], moved, t)
+	}
+}
+
+func TestSoftMask(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.SectionLength = 8
+	})
+
+	// Descending, so every sorted stretch comes out as its own ascending run.
+	// Unmasked, mid gray and light gray thirds: 8px, 4px and 2px stretches
+	masks := []uint8{0, 128, 191}
+	runStarts := func(rng *rand.Rand) []int {
+		seam := make([]types.PixelWithMask, 24)
+		for i := range seam {
+			seam[i] = gray(uint8(240 - i*10))
+			seam[i].Mask = masks[i/8]
+		}
+		intervals.SoftMask(seam, &intervals.State{Rand: rng})
+		starts := []int{0}
+		for i := 1; i < len(seam); i++ {
+			if seam[i].R < seam[i-1].R {
+				starts = append(starts, i)
+			}
+		}
+		return starts
+	}
+
+	// Rolling 0 sorts every stretch, so only the lengths show
+	if starts := runStarts(rand.New(fixedSource(0))); !slices.Equal(starts, []int{0, 8, 12, 16, 18, 20, 22}) {
+		t.Errorf("always sorting: runs start at %v", starts)
+	}
+	// Rolling just under 1 only sorts the unmasked stretch, the rest stay backwards
+	never := []int{0}
+	for i := 8; i < 24; i++ {
+		never = append(never, i)
+	}
+	if starts := runStarts(rand.New(fixedSource(^uint64(0)))); !slices.Equal(starts, never) {
+		t.Errorf("never sorting: runs start at %v", starts)
+	}
+}
+
+// a rand.Source that always rolls the same thing
+type fixedSource uint64
+
+func (source fixedSource) Uint64() uint64 {
+	return uint64(source)
+}
+
+// saves the whole config, lets set change it, and puts it back after the test
+func withConfig(t *testing.T, set func()) {
+	saved := shared.Config
+	t.Cleanup(func() {
+		shared.Config = saved
+	})
+	set()
+}
+
+func gray(v uint8) types.PixelWithMask {
+	return types.PixelWithMask{R: v, G: v, B: v, A: 255}
+}
+func compareSeams(expected, actual []types.PixelWithMask, t *testing.T) {
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Logf("expected: %v", expected)
+			t.Logf("actual: %v", actual)
+			t.Errorf("pixel %d differs. Expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
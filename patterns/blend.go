@@ -0,0 +1,31 @@
+package patterns
+
+import (
+	"image"
+)
+
+// the mask to load with for --mask_blend. everything should get sorted, so white
+// is topped out at 254 (255 is what gets skipped), but the gray stays so mask
+// driven intervals like softmask still work
+func BlendLoadMask(mask *image.Gray) *image.Gray {
+	loadMask := image.NewGray(mask.Rect)
+	for i, m := range mask.Pix {
+		loadMask.Pix[i] = min(m, 254)
+	}
+	return loadMask
+}
+
+// mixes original back into sorted by the mask, for --mask_blend
+//
+// white keeps the original, black keeps the sort, and gray lands in between,
+// so feathered masks dont leave hard edges. sorted gets overwritten
+func BlendMask(sorted, original *image.RGBA, mask *image.Gray) {
+	for i, m := range mask.Pix {
+		keep := uint32(m)
+		/// r, g, b and a
+		for c := i * 4; c < i*4+4; c++ {
+			blended := uint32(sorted.Pix[c])*(255-keep) + uint32(original.Pix[c])*keep
+			sorted.Pix[c] = uint8((blended + 127) / 255)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package patterns
+
+import (
+	"image"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// carves shared.Config.SeamCount vertical seams out of the image, one
+// low-energy seam at a time, like seam carving's seam removal
+//
+// each carved seam is its own interval and the rest of the image is left alone;
+// 0 seams carves the whole image
+func LoadCarve(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds()
+	paths := carvePaths(EdgeMagnitude(img), dims.Dx(), dims.Dy(), shared.Config.SeamCount, false)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveCarve(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// carve's horizontal twin, seams run left to right
+//
+// landscapes sort much nicer along the horizon
+func LoadCarveHorizontal(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds()
+	paths := carvePaths(EdgeMagnitude(img), dims.Dx(), dims.Dy(), shared.Config.SeamCount, true)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveCarveHorizontal(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// repeatedly finds the cheapest seam through the pixels that are left and removes it
+//
+// vertical seams run top to bottom, horizontal ones left to right. energy is EdgeMagnitude,
+// taken once over the original image and isnt recalculated after a removal, so only the
+// first seam is a true minimum-energy seam. the rest are the cheapest paths through
+// whats left, going by the original energy
+func carvePaths(energy *image.Gray, width, height, count int, horizontal bool) [][]int {
+	/// "along" is the seam direction, "across" is what seams get removed from
+	along, across := height, width
+	if horizontal {
+		along, across = width, height
+	}
+	index := func(i, j int) int {
+		if horizontal {
+			return j*width + i
+		}
+		return i*width + j
+	}
+	if count <= 0 || count > across {
+		count = across
+	}
+	if along == 0 {
+		return nil
+	}
+
+	/// each line's energy stays put, cols maps what's left of each line (in order)
+	/// back to the original columns
+	energies := make([][]int32, along)
+	cols := make([][]int32, along)
+	sums := make([][]int32, along)
+	energyRows := make([]int32, along*across)
+	colRows := make([]int32, along*across)
+	sumRows := make([]int32, along*across)
+	for i := range energies {
+		energies[i] = energyRows[i*across : (i+1)*across]
+		cols[i] = colRows[i*across : (i+1)*across]
+		sums[i] = sumRows[i*across : (i+1)*across]
+		for j := range energies[i] {
+			energies[i][j] = int32(energy.Pix[index(i, j)])
+			cols[i][j] = int32(j)
+		}
+	}
+
+	paths := make([][]int, 0, count)
+	for n := 0; n < count; n++ {
+		currWidth := across - n
+
+		/// cumulative cheapest energy to reach each pixel
+		for k, j := range cols[0][:currWidth] {
+			sums[0][k] = energies[0][j]
+		}
+		for i := 1; i < along; i++ {
+			lineCols := cols[i][:currWidth]
+			lineEnergy := energies[i]
+			prev := sums[i-1][:currWidth]
+			curr := sums[i][:currWidth]
+			if currWidth == 1 {
+				curr[0] = prev[0] + lineEnergy[lineCols[0]]
+				continue
+			}
+			/// edges only have 2 parents
+			curr[0] = min(prev[0], prev[1]) + lineEnergy[lineCols[0]]
+			for k := 1; k < currWidth-1; k++ {
+				curr[k] = min(prev[k-1], prev[k], prev[k+1]) + lineEnergy[lineCols[k]]
+			}
+			last := currWidth - 1
+			curr[last] = min(prev[last-1], prev[last]) + lineEnergy[lineCols[last]]
+		}
+
+		/// walk back from the cheapest end
+		seam := make([]int, along)
+		k := 0
+		last := sums[along-1][:currWidth]
+		for j := range last {
+			if last[j] < last[k] {
+				k = j
+			}
+		}
+		for i := along - 1; i >= 0; i-- {
+			if i < along-1 {
+				prev := sums[i]
+				next := k
+				if k > 0 && prev[k-1] < prev[next] {
+					next = k - 1
+				}
+				if k < currWidth-1 && prev[k+1] < prev[next] {
+					next = k + 1
+				}
+				k = next
+			}
+			seam[i] = k
+		}
+
+		/// remove it, only the remap shifts
+		path := make([]int, along)
+		for i, k := range seam {
+			path[i] = index(i, int(cols[i][k]))
+			copy(cols[i][k:currWidth-1], cols[i][k+1:currWidth])
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,119 @@
+package patterns
+
+import (
+	"image"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// loads bands of pixels along the contours where the image crosses
+// shared.Config.Contour.Level, found with marching squares
+//
+// each connected band is one seam. everything off the contours goes in one
+// last seam that's masked off, so it's left alone
+func LoadContour(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	field := grayscale(img)
+	if shared.Config.Contour.Source == "edges" {
+		field = EdgeMagnitude(img)
+	}
+
+	paths, leftovers := contourPaths(field, uint8(shared.Config.Contour.Level*255), shared.Config.Contour.Width)
+	return loadPathsWithSkipped(img, mask, paths, leftovers)
+}
+func SaveContour(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// returns the contour bands, then every pixel that isn't on one
+func contourPaths(field *image.Gray, level uint8, bandWidth int) ([][]int, []int) {
+	width := field.Rect.Dx()
+	height := field.Rect.Dy()
+	inside := func(x, y int) bool {
+		return field.Pix[y*width+x] >= level
+	}
+
+	/// marching squares: any 2x2 cell with corners on both sides of the
+	/// level has the contour running through it
+	band := make([]bool, width*height)
+	for y := 0; y < height-1; y++ {
+		for x := 0; x < width-1; x++ {
+			corners := 0
+			for _, corner := range [4][2]int{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}} {
+				if inside(corner[0], corner[1]) {
+					corners++
+				}
+			}
+			if corners == 0 || corners == 4 {
+				continue
+			}
+			band[y*width+x] = true
+			band[y*width+x+1] = true
+			band[(y+1)*width+x] = true
+			band[(y+1)*width+x+1] = true
+		}
+	}
+
+	/// fatten the bands
+	for grow := 1; grow < bandWidth; grow++ {
+		grown := make([]bool, len(band))
+		copy(grown, band)
+		for index, onBand := range band {
+			if !onBand {
+				continue
+			}
+			forNeighbors(index, width, height, func(neighbor int) {
+				grown[neighbor] = true
+			})
+		}
+		band = grown
+	}
+
+	/// walk each connected band depth-first, which traces along thin bands
+	visited := make([]bool, width*height)
+	paths := make([][]int, 0)
+	for start, onBand := range band {
+		if !onBand || visited[start] {
+			continue
+		}
+		path := make([]int, 0)
+		stack := []int{start}
+		for len(stack) > 0 {
+			index := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[index] {
+				continue
+			}
+			visited[index] = true
+			path = append(path, index)
+			forNeighbors(index, width, height, func(neighbor int) {
+				if band[neighbor] && !visited[neighbor] {
+					stack = append(stack, neighbor)
+				}
+			})
+		}
+		paths = append(paths, path)
+	}
+
+	leftovers := make([]int, 0)
+	for index, onBand := range band {
+		if !onBand {
+			leftovers = append(leftovers, index)
+		}
+	}
+	return paths, leftovers
+}
+
+// calls fn with each of the (up to) 8 pixels around index
+func forNeighbors(index, width, height int, fn func(neighbor int)) {
+	x, y := index%width, index/width
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny := x+dx, y+dy
+			if (dx == 0 && dy == 0) || nx < 0 || nx >= width || ny < 0 || ny >= height {
+				continue
+			}
+			fn(ny*width + nx)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package patterns
+
+import (
+	"image"
+
+	"pixorder/types"
+)
+
+/// space-filling curves, each one turns the whole image into a single seam
+
+// loads along a generalized hilbert curve, which works for any dimensions
+func LoadHilbert(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := [][]int{hilbertPath(dims.X, dims.Y)}
+	return loadPaths(img, mask, paths), paths
+}
+func SaveHilbert(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// loads along a peano curve
+//
+// the curve covers the smallest power-of-3 square around the image, whatever
+// falls outside is skipped, so the seam can jump where the image gets cut off
+func LoadPeano(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := [][]int{peanoPath(dims.X, dims.Y)}
+	return loadPaths(img, mask, paths), paths
+}
+func SavePeano(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// loads rows as one seam, alternating left-to-right and right-to-left
+func LoadSnake(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	path := make([]int, 0, dims.X*dims.Y)
+	for y := 0; y < dims.Y; y++ {
+		for x := 0; x < dims.X; x++ {
+			if y%2 == 0 {
+				path = append(path, y*dims.X+x)
+			} else {
+				path = append(path, y*dims.X+dims.X-1-x)
+			}
+		}
+	}
+	paths := [][]int{path}
+	return loadPaths(img, mask, paths), paths
+}
+func SaveSnake(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// based on https://github.com/jakubcerveny/gilbert
+// the code is under bsd-2-clause, copyright (c) 2018 jakub červený
+func hilbertPath(width, height int) []int {
+	path := make([]int, 0, width*height)
+	visit := func(x, y int) {
+		path = append(path, y*width+x)
+	}
+	if width >= height {
+		gilbert(0, 0, width, 0, 0, height, visit)
+	} else {
+		gilbert(0, 0, 0, height, width, 0, visit)
+	}
+	return path
+}
+
+// fills the rectangle at (x, y) spanned by the major axis a and minor axis b
+func gilbert(x, y, ax, ay, bx, by int, visit func(x, y int)) {
+	w := abs(ax + ay)
+	h := abs(bx + by)
+	/// unit major and minor directions
+	dax, day := sign(ax), sign(ay)
+	dbx, dby := sign(bx), sign(by)
+
+	/// trivial row/column fills
+	if h == 1 {
+		for i := 0; i < w; i++ {
+			visit(x, y)
+			x, y = x+dax, y+day
+		}
+		return
+	}
+	if w == 1 {
+		for i := 0; i < h; i++ {
+			visit(x, y)
+			x, y = x+dbx, y+dby
+		}
+		return
+	}
+
+	ax2, ay2 := floorHalf(ax), floorHalf(ay)
+	bx2, by2 := floorHalf(bx), floorHalf(by)
+	w2 := abs(ax2 + ay2)
+	h2 := abs(bx2 + by2)
+
+	if 2*w > 3*h {
+		/// long case: split in two along the major axis
+		if w2%2 != 0 && w > 2 {
+			/// prefer even steps
+			ax2, ay2 = ax2+dax, ay2+day
+		}
+		gilbert(x, y, ax2, ay2, bx, by, visit)
+		gilbert(x+ax2, y+ay2, ax-ax2, ay-ay2, bx, by, visit)
+	} else {
+		/// standard case: one step up, one long horizontal, one step down
+		if h2%2 != 0 && h > 2 {
+			bx2, by2 = bx2+dbx, by2+dby
+		}
+		gilbert(x, y, bx2, by2, ax2, ay2, visit)
+		gilbert(x+bx2, y+by2, ax, ay, bx-bx2, by-by2, visit)
+		gilbert(x+(ax-dax)+(bx2-dbx), y+(ay-day)+(by2-dby), -bx2, -by2, -(ax - ax2), -(ay - ay2), visit)
+	}
+}
+
+func peanoPath(width, height int) []int {
+	size := 1
+	for size < width || size < height {
+		size *= 3
+	}
+	path := make([]int, 0, width*height)
+	peano(0, 0, size, false, false, func(x, y int) {
+		if x < width && y < height {
+			path = append(path, y*width+x)
+		}
+	})
+	return path
+}
+
+// walks a size*size block as 3x3 sub-blocks, column by column in a serpentine,
+// mirroring sub-blocks so each one ends next to where the next one starts
+func peano(x, y, size int, flipX, flipY bool, visit func(x, y int)) {
+	if size == 1 {
+		visit(x, y)
+		return
+	}
+	third := size / 3
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 3; k++ {
+			/// odd columns run backwards
+			j := k
+			if i%2 == 1 {
+				j = 2 - k
+			}
+			subX, subY := i, j
+			if flipX {
+				subX = 2 - i
+			}
+			if flipY {
+				subY = 2 - j
+			}
+			peano(x+subX*third, y+subY*third, third, flipX != (j%2 == 1), flipY != (i%2 == 1), visit)
+		}
+	}
+}
+
+/// math utils
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	} else if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+// n / 2 rounded towards -∞, go's / rounds towards 0
+func floorHalf(n int) int {
+	return n >> 1
+}
@@ -0,0 +1,119 @@
+package patterns
+
+import (
+	"image"
+	"math"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// how far (in pixels) the gradient field gets blurred before tracing,
+// raw kernel responses are too noisy to follow
+const flowSmoothing = 2
+
+// traces streamlines along the image's contours, or straight up its gradient
+// with shared.Config.FlowGradient
+//
+// every pixel ends up on exactly one streamline
+func LoadFlow(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	paths := flowPaths(grayscale(img), shared.Config.FlowGradient)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveFlow(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+func flowPaths(grayed *image.Gray, alongGradient bool) [][]int {
+	width := grayed.Rect.Dx()
+	height := grayed.Rect.Dy()
+	horiz, vert := kernelResponses(grayed)
+
+	/// the vertical kernel measures change along x, the horizontal one change along -y
+	fieldX := make([]float64, width*height)
+	fieldY := make([]float64, width*height)
+	for i := range fieldX {
+		gradX := float64(vert[i])
+		gradY := float64(-horiz[i])
+		if alongGradient {
+			fieldX[i], fieldY[i] = gradX, gradY
+		} else {
+			/// contours run perpendicular to the gradient
+			fieldX[i], fieldY[i] = -gradY, gradX
+		}
+	}
+	fieldX = boxBlur(fieldX, width, height, flowSmoothing)
+	fieldY = boxBlur(fieldY, width, height, flowSmoothing)
+
+	visited := make([]bool, width*height)
+	paths := make([][]int, 0)
+	for start := range visited {
+		if visited[start] {
+			continue
+		}
+		x, y := start%width, start/width
+		path := []int{start}
+		visited[start] = true
+		/// flat areas keep going the way they were
+		prevX, prevY := 1.0, 0.0
+		for {
+			index := y*width + x
+			dirX, dirY := fieldX[index], fieldY[index]
+			length := math.Hypot(dirX, dirY)
+			if length == 0 {
+				dirX, dirY = prevX, prevY
+			} else {
+				dirX, dirY = dirX/length, dirY/length
+				/// contours dont have a direction, so dont turn back on ourselves
+				if dirX*prevX+dirY*prevY < 0 {
+					dirX, dirY = -dirX, -dirY
+				}
+			}
+
+			/// step to whichever neighbor is closest to the direction
+			nextX := x + int(math.Round(dirX))
+			nextY := y + int(math.Round(dirY))
+			if nextX < 0 || nextX >= width || nextY < 0 || nextY >= height {
+				break
+			}
+			next := nextY*width + nextX
+			if visited[next] {
+				break
+			}
+			visited[next] = true
+			path = append(path, next)
+			x, y = nextX, nextY
+			prevX, prevY = dirX, dirY
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// averages each value with its neighbors within radius, rows then columns
+func boxBlur(values []float64, width, height, radius int) []float64 {
+	rows := make([]float64, len(values))
+	for y := 0; y < height; y++ {
+		line := values[y*width : (y+1)*width]
+		for x := 0; x < width; x++ {
+			sum := 0.0
+			lo, hi := max(0, x-radius), min(width-1, x+radius)
+			for k := lo; k <= hi; k++ {
+				sum += line[k]
+			}
+			rows[y*width+x] = sum / float64(hi-lo+1)
+		}
+	}
+	blurred := make([]float64, len(values))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			sum := 0.0
+			lo, hi := max(0, y-radius), min(height-1, y+radius)
+			for k := lo; k <= hi; k++ {
+				sum += rows[k*width+x]
+			}
+			blurred[y*width+x] = sum / float64(hi-lo+1)
+		}
+	}
+	return blurred
+}
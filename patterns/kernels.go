@@ -0,0 +1,87 @@
+package patterns
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+/// edge detection shared by the seam patterns, flow, contour and the edges interval
+
+// strongest response EdgeMagnitude can see, a hard black/white edge on both kernels
+var maxKernelMagnitude = math.Hypot(3*255, 3*255)
+
+// how edgy every pixel is, 0 for flat to 255 for a hard edge
+func EdgeMagnitude(img *image.RGBA) *image.Gray {
+	grayed := grayscale(img)
+	horiz, vert := kernelResponses(grayed)
+	edges := image.NewGray(grayed.Rect)
+	for i := range edges.Pix {
+		magnitude := math.Hypot(float64(horiz[i]), float64(vert[i]))
+		edges.Pix[i] = uint8(min(255, magnitude*255/maxKernelMagnitude))
+	}
+	return edges
+}
+
+func grayscale(img *image.RGBA) *image.Gray {
+	dims := img.Bounds()
+	grayed := image.NewGray(dims)
+	draw.Draw(grayed, grayed.Bounds(), img.SubImage(dims), dims.Min, draw.Src)
+	return grayed
+}
+
+// seam carving util func
+func runKernels(img *image.Gray) {
+	horiz, vert := kernelResponses(img)
+	width := img.Bounds().Max.X
+
+	/// merge
+	for y := 1; y < img.Bounds().Max.Y; y++ {
+		for x := 1; x < width; x++ {
+			index := y*width + x
+			img.Set(x, y, color.Gray{Y: uint8(horiz[index]) + uint8(vert[index])})
+		}
+	}
+}
+
+// raw edge detection sums, horizontal edges then vertical ones
+//
+// neighbours past the border are clamped to the nearest pixel on the same row or column
+func kernelResponses(img *image.Gray) ([]int, []int) {
+	/// kernels are black magic
+	vertKernel := [][]int8{
+		{-1, 0, 1},
+		{-1, 0, 1},
+		{-1, 0, 1},
+	}
+	horizKernel := [][]int8{
+		{1, 1, 1},
+		{0, 0, 0},
+		{-1, -1, -1},
+	}
+
+	/// edge detect
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+	hImg := make([]int, width*height)
+	vImg := make([]int, width*height)
+	for y := range height {
+		for x := range width {
+			hSum, vSum := 0, 0
+			for ky := -1; ky <= 1; ky++ {
+				for kx := -1; kx <= 1; kx++ {
+					/// x and y get clamped on their own so nothing wraps onto the next row
+					sx := min(max(x+kx, 0), width-1)
+					sy := min(max(y+ky, 0), height-1)
+					val := int(img.Pix[sy*img.Stride+sx])
+					hSum += int(horizKernel[ky+1][kx+1]) * val
+					vSum += int(vertKernel[ky+1][kx+1]) * val
+				}
+			}
+			hImg[y*width+x] = hSum
+			vImg[y*width+x] = vSum
+		}
+	}
+	return hImg, vImg
+}
@@ -0,0 +1,55 @@
+package patterns
+
+import (
+	"image"
+	"math"
+	"math/rand/v2"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// loads rows that undulate along 1d perlin noise,
+// row + shared.Config.WaveAmplitude * noise(x * shared.Config.WaveFrequency)
+//
+// every column is shifted by the same amount for every row, so the rows
+// still tile the image without overlapping
+func LoadWavy(img *image.RGBA, mask *image.Gray, seed int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	noise := newNoise1D(rand.New(rand.NewPCG(uint64(seed), 0)))
+	offsets := make([]int, dims.X)
+	for x := range offsets {
+		offsets[x] = int(math.Round(shared.Config.WaveAmplitude * noise.at(float64(x)*shared.Config.WaveFrequency)))
+	}
+	paths := shearedPaths(dims.X, dims.Y, offsets, false, false)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveWavy(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// classic perlin noise, squished down to one dimension
+type noise1D struct {
+	gradients [256]float64
+}
+
+func newNoise1D(rng *rand.Rand) *noise1D {
+	noise := &noise1D{}
+	for i := range noise.gradients {
+		noise.gradients[i] = rng.Float64()*2 - 1
+	}
+	return noise
+}
+
+// roughly in [-1, 1], and 0 on every integer
+func (noise *noise1D) at(x float64) float64 {
+	cell := math.Floor(x)
+	frac := x - cell
+	/// wraps every 256 cells
+	left := noise.gradients[int(cell)&255]
+	right := noise.gradients[int(cell+1)&255]
+
+	/// smootherstep between the two gradients
+	fade := frac * frac * frac * (frac*(frac*6-15) + 10)
+	return 2 * (left*frac + fade*(right*(frac-1)-left*frac))
+}
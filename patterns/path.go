@@ -0,0 +1,285 @@
+package patterns
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// how many straight segments each svg curve gets flattened into
+const curveSegments = 16
+
+// loads strokes along the polylines in shared.Config.Polylines, each
+// shared.Config.StrokeWidth pixels wide
+//
+// each polyline is one seam. pixels are claimed by the first stroke to reach
+// them, and everything no stroke reached goes in one last masked off seam
+func LoadPath(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths, skipped := strokePaths(dims.X, dims.Y, shared.Config.Polylines, shared.Config.StrokeWidth)
+	return loadPathsWithSkipped(img, mask, paths, skipped)
+}
+func SavePath(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// reads polylines from a json file ([[[x, y], ...], ...]) or an svg file
+// (<polyline> points and <path> data, M/L/H/V/C/Z), in pixel coordinates
+//
+// lone points (like an svg M with nothing after it) dont go anywhere, so
+// theyre dropped from either format
+func ReadPolylines(file string) ([]types.Polyline, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	polylines := make([]types.Polyline, 0)
+	if strings.EqualFold(filepath.Ext(file), ".svg") {
+		polylines, err = parseSvg(raw)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(raw, &polylines); err != nil {
+		return nil, fmt.Errorf("couldn't parse %q as json: %w", file, err)
+	}
+	return slices.DeleteFunc(polylines, func(polyline types.Polyline) bool {
+		return len(polyline) < 2
+	}), nil
+}
+
+func strokePaths(width, height int, polylines []types.Polyline, strokeWidth float64) ([][]int, []int) {
+	radius := strokeWidth / 2
+	claimed := make([]bool, width*height)
+	paths := make([][]int, 0, len(polylines))
+
+	for _, polyline := range polylines {
+		path := make([]int, 0)
+		/// stamp a disc every half pixel along the line
+		stamp := func(px, py float64) {
+			minX, maxX := max(0, int(math.Floor(px-radius))), min(width-1, int(math.Ceil(px+radius)))
+			minY, maxY := max(0, int(math.Floor(py-radius))), min(height-1, int(math.Ceil(py+radius)))
+			for y := minY; y <= maxY; y++ {
+				for x := minX; x <= maxX; x++ {
+					dx, dy := float64(x)+0.5-px, float64(y)+0.5-py
+					/// always grab the pixel under the line, however thin
+					under := x == int(math.Floor(px)) && y == int(math.Floor(py))
+					if claimed[y*width+x] || (!under && dx*dx+dy*dy > radius*radius) {
+						continue
+					}
+					claimed[y*width+x] = true
+					path = append(path, y*width+x)
+				}
+			}
+		}
+		for i, point := range polyline {
+			if i == 0 {
+				stamp(point[0], point[1])
+				continue
+			}
+			prev := polyline[i-1]
+			steps := max(1, int(math.Ceil(2*math.Hypot(point[0]-prev[0], point[1]-prev[1]))))
+			for step := 1; step <= steps; step++ {
+				t := float64(step) / float64(steps)
+				stamp(prev[0]+(point[0]-prev[0])*t, prev[1]+(point[1]-prev[1])*t)
+			}
+		}
+		if len(path) > 0 {
+			paths = append(paths, path)
+		}
+	}
+
+	skipped := make([]int, 0)
+	for index, isClaimed := range claimed {
+		if !isClaimed {
+			skipped = append(skipped, index)
+		}
+	}
+	return paths, skipped
+}
+
+/// svg parsing
+
+func parseSvg(raw []byte) ([]types.Polyline, error) {
+	polylines := make([]types.Polyline, 0)
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't parse svg: %w", err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range element.Attr {
+			switch {
+			case element.Name.Local == "path" && attr.Name.Local == "d":
+				parsed, err := parsePathData(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				polylines = append(polylines, parsed...)
+			case element.Name.Local == "polyline" && attr.Name.Local == "points":
+				numbers, err := parseNumbers(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				polyline := make(types.Polyline, 0, len(numbers)/2)
+				for i := 0; i+1 < len(numbers); i += 2 {
+					polyline = append(polyline, [2]float64{numbers[i], numbers[i+1]})
+				}
+				polylines = append(polylines, polyline)
+			}
+		}
+	}
+	return polylines, nil
+}
+
+// every subpath (each M) becomes its own polyline
+func parsePathData(data string) ([]types.Polyline, error) {
+	polylines := make([]types.Polyline, 0)
+	current := types.Polyline{}
+	x, y := 0.0, 0.0
+	startX, startY := 0.0, 0.0
+
+	/// split into commands, each followed by its numbers
+	commands := make([]string, 0)
+	last := -1
+	for i, r := range data {
+		if unicode.IsLetter(r) && r != 'e' && r != 'E' {
+			if last >= 0 {
+				commands = append(commands, data[last:i])
+			}
+			last = i
+		}
+	}
+	if last >= 0 {
+		commands = append(commands, data[last:])
+	}
+
+	finish := func() {
+		if len(current) > 0 {
+			polylines = append(polylines, current)
+		}
+		current = types.Polyline{}
+	}
+	for _, command := range commands {
+		op := rune(command[0])
+		args, err := parseNumbers(command[1:])
+		if err != nil {
+			return nil, err
+		}
+		relative := unicode.IsLower(op)
+		offsetX, offsetY := 0.0, 0.0
+		if relative {
+			offsetX, offsetY = x, y
+		}
+		switch unicode.ToUpper(op) {
+		case 'M':
+			finish()
+			for i := 0; i+1 < len(args); i += 2 {
+				if relative {
+					offsetX, offsetY = x, y
+				}
+				x, y = args[i]+offsetX, args[i+1]+offsetY
+				if i == 0 {
+					startX, startY = x, y
+				}
+				current = append(current, [2]float64{x, y})
+			}
+		case 'L':
+			for i := 0; i+1 < len(args); i += 2 {
+				if relative {
+					offsetX, offsetY = x, y
+				}
+				x, y = args[i]+offsetX, args[i+1]+offsetY
+				current = append(current, [2]float64{x, y})
+			}
+		case 'H':
+			for _, arg := range args {
+				if relative {
+					offsetX = x
+				}
+				x = arg + offsetX
+				current = append(current, [2]float64{x, y})
+			}
+		case 'V':
+			for _, arg := range args {
+				if relative {
+					offsetY = y
+				}
+				y = arg + offsetY
+				current = append(current, [2]float64{x, y})
+			}
+		case 'C':
+			for i := 0; i+5 < len(args); i += 6 {
+				if relative {
+					offsetX, offsetY = x, y
+				}
+				x1, y1 := args[i]+offsetX, args[i+1]+offsetY
+				x2, y2 := args[i+2]+offsetX, args[i+3]+offsetY
+				endX, endY := args[i+4]+offsetX, args[i+5]+offsetY
+				/// flatten the bezier
+				for step := 1; step <= curveSegments; step++ {
+					t := float64(step) / curveSegments
+					mt := 1 - t
+					current = append(current, [2]float64{
+						mt*mt*mt*x + 3*mt*mt*t*x1 + 3*mt*t*t*x2 + t*t*t*endX,
+						mt*mt*mt*y + 3*mt*mt*t*y1 + 3*mt*t*t*y2 + t*t*t*endY,
+					})
+				}
+				x, y = endX, endY
+			}
+		case 'Z':
+			x, y = startX, startY
+			current = append(current, [2]float64{x, y})
+		default:
+			return nil, fmt.Errorf("unsupported svg path command %q", op)
+		}
+	}
+	finish()
+	return polylines, nil
+}
+
+// numbers separated by commas and/or whitespace, or just a sign
+func parseNumbers(s string) ([]float64, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	numbers := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		/// "10-5" is 2 numbers in svg
+		start := 0
+		for i := 1; i < len(field); i++ {
+			if (field[i] == '-' || field[i] == '+') && field[i-1] != 'e' && field[i-1] != 'E' {
+				n, err := strconv.ParseFloat(field[start:i], 64)
+				if err != nil {
+					return nil, fmt.Errorf("bad number %q in svg: %w", field[start:i], err)
+				}
+				numbers = append(numbers, n)
+				start = i
+			}
+		}
+		n, err := strconv.ParseFloat(field[start:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q in svg: %w", field[start:], err)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
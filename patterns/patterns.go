@@ -2,28 +2,72 @@ package patterns
 
 import (
 	"image"
-	"image/color"
 	"image/draw"
 	"math"
+	"slices"
 
+	"pixorder/shared"
 	"pixorder/types"
 )
 // spits out seams to be sorted
 //
+// seed is the image's own seed for the random patterns, see intervals.ImageSeed.
 // second return value is arbitrary data persisted between *load and *save
-var Loader = map[string]func(img *image.RGBA, mask *image.Gray) (*[][]types.PixelWithMask, any){
-	"rowload":    LoadRow,
-	"spiralload": LoadSpiral,
-	"seamload":   LoadSeamCarving,
+var Loader = map[string]func(img *image.RGBA, mask *image.Gray, seed int64) (*[][]types.PixelWithMask, any){
+	"rowload":          LoadRow,
+	"wavyload":         LoadWavy,
+	"columnload":       LoadColumn,
+	"diagonalload":     LoadDiagonal,
+	"antidiagonalload": LoadAntidiagonal,
+	"lineload":         LoadLine,
+	"ringsload":        LoadRings,
+	"raysload":         LoadRays,
+	"polarload":        LoadPolar,
+	"spiralload":       LoadSpiral,
+	"seamload":         LoadSeamCarving,
+	"carveload":        LoadCarve,
+	"carvehload":       LoadCarveHorizontal,
+	"seamhload":        LoadCarveHorizontal, /// carveh's original name
+	"hilbertload":      LoadHilbert,
+	"peanoload":        LoadPeano,
+	"snakeload":        LoadSnake,
+	"flowload":         LoadFlow,
+	"voronoiload":      LoadVoronoi,
+	"superpixelload":   LoadSuperpixel,
+	"tilesload":        LoadTiles,
+	"quadtreeload":     LoadQuadtree,
+	"contourload":      LoadContour,
+	"pathload":         LoadPath,
 }
 // puts sorted seams back in the right place
 var Saver = map[string]func(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA{
-	"rowsave":    SaveRow,
-	"spiralsave": SaveSpiral,
-	"seamsave":   SaveSeamCarving,
+	"rowsave":          SaveRow,
+	"wavysave":         SaveWavy,
+	"columnsave":       SaveColumn,
+	"diagonalsave":     SaveDiagonal,
+	"antidiagonalsave": SaveAntidiagonal,
+	"linesave":         SaveLine,
+	"ringssave":        SaveRings,
+	"rayssave":         SaveRays,
+	"polarsave":        SavePolar,
+	"spiralsave":       SaveSpiral,
+	"seamsave":         SaveSeamCarving,
+	"carvesave":        SaveCarve,
+	"carvehsave":       SaveCarveHorizontal,
+	"seamhsave":        SaveCarveHorizontal,
+	"hilbertsave":      SaveHilbert,
+	"peanosave":        SavePeano,
+	"snakesave":        SaveSnake,
+	"flowsave":         SaveFlow,
+	"voronoisave":      SaveVoronoi,
+	"superpixelsave":   SaveSuperpixel,
+	"tilessave":        SaveTiles,
+	"quadtreesave":     SaveQuadtree,
+	"contoursave":      SaveContour,
+	"pathsave":         SavePath,
 }
 // loads entire rows
-func LoadRow(img *image.RGBA, mask *image.Gray) (*[][]types.PixelWithMask, any) {
+func LoadRow(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
 	dims := img.Bounds().Max
 	/// split image into rows
 	rows := make([][]types.PixelWithMask, dims.Y)
@@ -48,95 +92,197 @@ func SaveRow(outputImg *image.RGBA, rows *[][]types.PixelWithMask, dims image.Re
 	return outputImg
 }
 
-// based on https://github.com/jeffThompson/PixelSorting/blob/master/SpiralSortPixels/SpiralSortPixels.pde
-// prayge, i'm not a mathy fomx
-// lots of help from fren fixing it
-// the code is under cc-by-nc-sa 3.0 ig? https://creativecommons.org/licenses/by-nc-sa/3.0/
-// loads in a t-r-b-l spiral
-func LoadSpiral(img *image.RGBA, mask *image.Gray) (*[][]types.PixelWithMask, any) {
+// loads entire columns, top to bottom
+func LoadColumn(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
 	dims := img.Bounds().Max
-	width := dims.X
-	height := dims.Y
+	/// split image into columns
+	columns := make([][]types.PixelWithMask, dims.X)
+	for x := 0; x < dims.X; x++ {
+		column := make([]types.PixelWithMask, dims.Y)
 
-	seams := make([][]types.PixelWithMask, 0)
+		for y := 0; y < dims.Y; y++ {
+			pixel := img.RGBAAt(x, y)
+			masked := mask.GrayAt(x, y).Y
+			column[y] = types.PixelWithMaskFromColor(pixel, masked)
+		}
+		columns[x] = column
+	}
+	return &columns, nil
+}
+func SaveColumn(outputImg *image.RGBA, columns *[][]types.PixelWithMask, dims image.Rectangle, _ ...any) *image.RGBA {
+	for i, column := range *columns {
+		for j, currPixWithMask := range column {
+			outputImg.SetRGBA(i, j, currPixWithMask.ToColor())
+		}
+	}
+	return outputImg
+}
 
-	max := int(math.Min(float64(height), float64(width))) / 2
-	for offset := 0; offset <= max; offset++ {
-		seam := make([]types.PixelWithMask, 0)
+// loads 45° diagonals, top-left to bottom-right
+//
+// starts at the bottom-left corner and works towards the top-right
+func LoadDiagonal(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	width := dims.X
+	height := dims.Y
 
-		top := offset
-		bottom := height - offset - 1
-		left := offset
-		right := width - offset - 1
+	diagonals := make([][]types.PixelWithMask, 0, width+height-1)
+	/// d is x - y, constant along each diagonal
+	for d := -(height - 1); d < width; d++ {
+		x := max(d, 0)
+		y := x - d
+		diagonal := make([]types.PixelWithMask, 0, min(width-x, height-y))
 
-		/// right
-		for x := left; x <= right; x++ {
-			pixel := img.RGBAAt(x, top)
-			maskVal := mask.GrayAt(x, top).Y
-			seam = append(seam, types.PixelWithMaskFromColor(pixel, maskVal))
-		}
-		/// down
-		for y := top + 1; y <= bottom; y++ {
-			pixel := img.RGBAAt(right, y)
-			maskVal := mask.GrayAt(right, y).Y
-			seam = append(seam, types.PixelWithMaskFromColor(pixel, maskVal))
+		for ; x < width && y < height; x, y = x+1, y+1 {
+			pixel := img.RGBAAt(x, y)
+			masked := mask.GrayAt(x, y).Y
+			diagonal = append(diagonal, types.PixelWithMaskFromColor(pixel, masked))
 		}
-		/// left
-		for x := right - 1; x > left; x-- {
-			pixel := img.RGBAAt(x, bottom)
-			maskVal := mask.GrayAt(x, bottom).Y
-			seam = append(seam, types.PixelWithMaskFromColor(pixel, maskVal))
+		diagonals = append(diagonals, diagonal)
+	}
+	return &diagonals, nil
+}
+func SaveDiagonal(outputImg *image.RGBA, diagonals *[][]types.PixelWithMask, dims image.Rectangle, _ ...any) *image.RGBA {
+	height := dims.Max.Y
+
+	for i, diagonal := range *diagonals {
+		d := i - (height - 1)
+		x := max(d, 0)
+		y := x - d
+		for _, currPixWithMask := range diagonal {
+			outputImg.SetRGBA(x, y, currPixWithMask.ToColor())
+			x++
+			y++
 		}
-		/// up
-		for y := bottom; y > top; y-- {
-			pixel := img.RGBAAt(left, y)
-			maskVal := mask.GrayAt(left, y).Y
-			seam = append(seam, types.PixelWithMaskFromColor(pixel, maskVal))
+	}
+	return outputImg
+}
+
+// loads 45° antidiagonals, top-right to bottom-left
+//
+// starts at the top-left corner and works towards the bottom-right
+func LoadAntidiagonal(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	width := dims.X
+	height := dims.Y
+
+	antidiagonals := make([][]types.PixelWithMask, 0, width+height-1)
+	/// s is x + y, constant along each antidiagonal
+	for s := 0; s < width+height-1; s++ {
+		x := min(s, width-1)
+		y := s - x
+		antidiagonal := make([]types.PixelWithMask, 0, min(x+1, height-y))
+
+		for ; x >= 0 && y < height; x, y = x-1, y+1 {
+			pixel := img.RGBAAt(x, y)
+			masked := mask.GrayAt(x, y).Y
+			antidiagonal = append(antidiagonal, types.PixelWithMaskFromColor(pixel, masked))
 		}
+		antidiagonals = append(antidiagonals, antidiagonal)
+	}
+	return &antidiagonals, nil
+}
+func SaveAntidiagonal(outputImg *image.RGBA, antidiagonals *[][]types.PixelWithMask, dims image.Rectangle, _ ...any) *image.RGBA {
+	width := dims.Max.X
 
-		seams = append(seams, seam)
+	for s, antidiagonal := range *antidiagonals {
+		x := min(s, width-1)
+		y := s - x
+		for _, currPixWithMask := range antidiagonal {
+			outputImg.SetRGBA(x, y, currPixWithMask.ToColor())
+			x--
+			y++
+		}
 	}
+	return outputImg
+}
 
-	return &seams, nil
+// loads parallel lines at shared.Config.Angle degrees, clockwise from the x axis
+//
+// the lines are rasterized bresenham-style straight onto the pixel grid, so unlike
+// rotating the image nothing gets resampled
+func LoadLine(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := linePaths(dims.X, dims.Y, shared.Config.Angle)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveLine(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// based on https://github.com/jeffThompson/PixelSorting/blob/master/SpiralSortPixels/SpiralSortPixels.pde
+// prayge, i'm not a mathy fomx
+// lots of help from fren fixing it
+// the code is under cc-by-nc-sa 3.0 ig? https://creativecommons.org/licenses/by-nc-sa/3.0/
+// loads in a t-r-b-l spiral by default, see shared.Config.Spiral for the knobs
+func LoadSpiral(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := spiralPaths(dims.X, dims.Y, shared.Config.Spiral)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveSpiral(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
 }
-func SaveSpiral(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, _ ...any) *image.RGBA {
-	width := dims.Max.X
-	height := dims.Max.Y
 
-	for offset, seam := range *seams {
-		top := offset
-		bottom := height - offset - 1
-		left := offset
-		right := width - offset - 1
-		currPixIdx := 0
-
-		/// right
-		for x := left; x <= right; x++ {
-			outputImg.Set(x, top, seam[currPixIdx].ToColor())
-			currPixIdx++
+// builds the rings clockwise from the top left, then flips/rotates
+// them to start from the right corner going the right way
+func spiralPaths(width, height int, config types.SpiralConfig) [][]int {
+	clockwise := config.Direction != "ccw"
+	/// starting down (or up) a side means building the rings in a transposed frame
+	transposed := (config.Start == "tr" || config.Start == "bl") == clockwise
+	frameWidth, frameHeight := width, height
+	if transposed {
+		frameWidth, frameHeight = height, width
+	}
+	toImage := func(u, v int) (int, int) {
+		switch {
+		case config.Start == "tr" && clockwise:
+			return width - 1 - v, u
+		case config.Start == "br" && clockwise:
+			return width - 1 - u, height - 1 - v
+		case config.Start == "bl" && clockwise:
+			return v, height - 1 - u
+		case clockwise:
+			return u, v
+		case config.Start == "tr":
+			return width - 1 - u, v
+		case config.Start == "br":
+			return width - 1 - v, height - 1 - u
+		case config.Start == "bl":
+			return u, height - 1 - v
+		default:
+			return v, u
 		}
-		/// down
-		for y := top + 1; y <= bottom; y++ {
-			outputImg.Set(right, y, seam[currPixIdx].ToColor())
-			currPixIdx++
+	}
+
+	paths := rectSpiralPaths(image.Rect(0, 0, frameWidth, frameHeight), frameWidth)
+	for _, path := range paths {
+		for i, index := range path {
+			x, y := toImage(index%frameWidth, index/frameWidth)
+			path[i] = y*width + x
 		}
-		/// left
-		for x := right - 1; x > left; x-- {
-			outputImg.Set(x, bottom, seam[currPixIdx].ToColor())
-			currPixIdx++
+	}
+
+	if config.Continuous {
+		/// each ring ends right next to where the next one starts
+		whole := make([]int, 0, width*height)
+		for _, path := range paths {
+			whole = append(whole, path...)
 		}
-		/// up
-		for y := bottom; y > top; y-- {
-			outputImg.Set(left, y, seam[currPixIdx].ToColor())
-			currPixIdx++
+		paths = [][]int{whole}
+	}
+	if config.Outward {
+		slices.Reverse(paths)
+		for _, path := range paths {
+			slices.Reverse(path)
 		}
 	}
-
-	return outputImg
+	return paths
 }
+
 // finds the strongest path and loads using it
 // https://github.com/jeffThompson/PixelSorting/tree/master/SortThroughSeamCarving/SortThroughSeamCarving
-func LoadSeamCarving(img *image.RGBA, mask *image.Gray) (*[][]types.PixelWithMask, any) {
+func LoadSeamCarving(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
 	/// "//" comments copied over
 	dims := img.Bounds()
 
@@ -207,78 +353,164 @@ func SaveSeamCarving(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims
 	return outputImg
 }
 
-// seam carving util func
-func unrollImage(img *image.Gray) []color.Gray {
-	dims := img.Bounds().Max
-	pixels := make([]color.Gray, dims.X*dims.Y)
-	for y := 0; y < dims.Y; y++ {
-		for x := 0; x < dims.X; x++ {
-			pixel := img.GrayAt(x, y)
-			pixels[y*dims.X+x] = pixel
+/// path utils
+/// a path is a list of pixel indices (y*width + x) making up one seam
+
+// loads pixels along each path
+func loadPaths(img *image.RGBA, mask *image.Gray, paths [][]int) *[][]types.PixelWithMask {
+	seams := make([][]types.PixelWithMask, len(paths))
+	for i, path := range paths {
+		seam := make([]types.PixelWithMask, len(path))
+		for j, index := range path {
+			rawPix := img.Pix[index*4 : index*4+4]
+			seam[j] = types.PixelWithMask{
+				R:    rawPix[0],
+				G:    rawPix[1],
+				B:    rawPix[2],
+				A:    rawPix[3],
+				Mask: mask.Pix[index],
+			}
+		}
+		seams[i] = seam
+	}
+	return &seams
+}
+
+// loads paths plus one last seam of every skipped pixel, masked off so
+// sorting leaves it alone
+//
+// returns the seams along with the paths they came from
+func loadPathsWithSkipped(img *image.RGBA, mask *image.Gray, paths [][]int, skipped []int) (*[][]types.PixelWithMask, [][]int) {
+	paths = append(paths, skipped)
+	seams := loadPaths(img, mask, paths)
+	skippedSeam := (*seams)[len(*seams)-1]
+	for i := range skippedSeam {
+		skippedSeam[i].Mask = 255
+	}
+	return seams, paths
+}
+
+// writes each seam back along the path it was loaded from
+func savePaths(outputImg *image.RGBA, seams *[][]types.PixelWithMask, paths [][]int) *image.RGBA {
+	for i, seam := range *seams {
+		for j, sortedPix := range seam {
+			index := paths[i][j] * 4
+			outputImg.Pix[index] = sortedPix.R
+			outputImg.Pix[index+1] = sortedPix.G
+			outputImg.Pix[index+2] = sortedPix.B
+			outputImg.Pix[index+3] = sortedPix.A
 		}
 	}
-	return pixels
+	return outputImg
 }
-func runKernels(img *image.Gray) {
-	/// kernels are black magic
-	vertKernel := [][]int8{
-		{-1, 0, 1},
-		{-1, 0, 1},
-		{-1, 0, 1},
+
+// where in the image each seam pixel was loaded from, for intervals that look
+// things up per pixel (see intervals.State.Guide)
+//
+// path patterns already have them. for the rest, every seam pixel gets numbered
+// in its color and saved, and the numbers get read back out of the image. pixels
+// that dont land anywhere (or get written over by another seam) come out as -1
+func SeamPaths(pattern string, seams *[][]types.PixelWithMask, dims image.Rectangle, data any) [][]int {
+	if paths, ok := data.([][]int); ok {
+		return paths
 	}
-	horizKernel := [][]int8{
-		{1, 1, 1},
-		{0, 0, 0},
-		{-1, -1, -1},
+	total := 0
+	for _, seam := range *seams {
+		total += len(seam)
+	}
+	/// numbers start at 1, 0 is a pixel nothing was saved to
+	numbered := make([][]types.PixelWithMask, len(*seams))
+	number := uint32(1)
+	for i, seam := range *seams {
+		numbered[i] = make([]types.PixelWithMask, len(seam))
+		for j := range seam {
+			numbered[i][j] = types.PixelWithMask{R: uint8(number >> 24), G: uint8(number >> 16), B: uint8(number >> 8), A: uint8(number)}
+			number++
+		}
 	}
+	placed := Saver[pattern+"save"](image.NewRGBA(dims), &numbered, dims, data)
 
-	/// split image
-	vImg := unrollImage(img)
-	hImg := unrollImage(img)
+	indices := make([]int, total)
+	for i := range indices {
+		indices[i] = -1
+	}
+	for index := 0; index*4 < len(placed.Pix); index++ {
+		rawPix := placed.Pix[index*4 : index*4+4]
+		number := uint32(rawPix[0])<<24 | uint32(rawPix[1])<<16 | uint32(rawPix[2])<<8 | uint32(rawPix[3])
+		if number != 0 {
+			indices[number-1] = index
+		}
+	}
+	paths := make([][]int, len(*seams))
+	start := 0
+	for i, seam := range *seams {
+		paths[i] = indices[start : start+len(seam)]
+		start += len(seam)
+	}
+	return paths
+}
 
-	/// edge detect
-	dims := (*img).Bounds()
-	width := dims.Max.X
-	height := dims.Max.Y
-	totalLen := width * height
-	/// horiz
-	for y := 1; y < height; y++ {
-		for x := 1; x < width; x++ {
-			sum := 0
-			for ky := -1; ky <= 1; ky++ {
-				for kx := -1; kx <= 1; kx++ {
-					pos := min((y+ky)*width+(x+kx), totalLen-1)
-					val := (*img).Pix[pos]
-					sum += int(horizKernel[ky+1][kx+1]) * int(val)
-				}
-			}
-			hImg[y*width+x] = color.Gray{Y: uint8(sum)}
+// splits the image into parallel lines walking in the direction of angle
+func linePaths(width, height int, angle float64) [][]int {
+	rad := angle * math.Pi / 180
+	dx := math.Cos(rad)
+	dy := math.Sin(rad)
+
+	/// step along whichever axis moves faster, so lines never skip a pixel
+	if math.Abs(dx) >= math.Abs(dy) {
+		offsets := make([]int, width)
+		for x := range offsets {
+			offsets[x] = int(math.Round(float64(x) * dy / dx))
 		}
+		return shearedPaths(width, height, offsets, false, dx < 0)
 	}
-	/// then vert
-	for y := 1; y < height; y++ {
-		for x := 1; x < width; x++ {
-			sum := 0
-			for ky := -1; ky <= 1; ky++ {
-				for kx := -1; kx <= 1; kx++ {
-					pos := min((y+ky)*width+(x+kx), totalLen-1)
-					val := img.Pix[pos]
-					sum += int(vertKernel[ky+1][kx+1]) * int(val)
-				}
+	offsets := make([]int, height)
+	for y := range offsets {
+		offsets[y] = int(math.Round(float64(y) * dx / dy))
+	}
+	return shearedPaths(height, width, offsets, true, dy < 0)
+}
+
+// builds one path per shift of offsets across the image
+//
+// every path visits (i, shift+offsets[i]) along the major axis, and since
+// each i has exactly one offset, every pixel ends up in exactly one path
+func shearedPaths(length, across int, offsets []int, transposed, reversed bool) [][]int {
+	if length == 0 {
+		return nil
+	}
+	width := length
+	if transposed {
+		width = across
+	}
+	minOffset := slices.Min(offsets)
+	maxOffset := slices.Max(offsets)
+
+	paths := make([][]int, 0, across+maxOffset-minOffset)
+	for shift := -maxOffset; shift < across-minOffset; shift++ {
+		path := make([]int, 0)
+		for i := 0; i < length; i++ {
+			j := shift + offsets[i]
+			if j < 0 || j >= across {
+				continue
+			}
+			if transposed {
+				path = append(path, i*width+j)
+			} else {
+				path = append(path, j*width+i)
 			}
-			vImg[y*width+x] = color.Gray{Y: uint8(sum)}
 		}
-	}
-	/// merge
-	for y := 1; y < height; y++ {
-		for x := 1; x < width; x++ {
-			index := y*width + x
-			hPixel := hImg[index]
-			vPixel := vImg[index]
-			img.Set(x, y, color.Gray{Y: hPixel.Y + vPixel.Y})
+		if len(path) == 0 {
+			continue
+		}
+		if reversed {
+			slices.Reverse(path)
 		}
+		paths = append(paths, path)
 	}
+	return paths
 }
+
 func getSums(img *image.Gray, dims image.Point) [][]float32 {
 	width := dims.X
 	height := dims.Y
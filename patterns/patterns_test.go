@@ -4,11 +4,16 @@ package patterns_test
 import (
 	//"fmt"
 	"crypto/rand"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"image"
 	"image/color"
+	"pixorder/intervals"
 	"pixorder/patterns"
+	"pixorder/shared"
 	"pixorder/types"
 	// "pixorder/types"
 )
@@ -23,9 +28,127 @@ func TestLoadRow(t *testing.T) {
 		{input.RGBAAt(0, 1), input.RGBAAt(1, 1), input.RGBAAt(2, 1)},
 		{input.RGBAAt(0, 2), input.RGBAAt(1, 2), input.RGBAAt(2, 2)},
 	}
-	actual, _ := patterns.LoadRow(input, mask)
+	actual, _ := patterns.LoadRow(input, mask, 0)
 	compareLoadEquality(input, expected, actual, t)
 }
+func TestLoadWavy(t *testing.T) {
+	WIDTH, HEIGHT := 16, 6
+	withConfig(t, func() {
+		shared.Config.WaveAmplitude = 3
+		shared.Config.WaveFrequency = 0.3
+	})
+	input := genTestPic(WIDTH, HEIGHT, t)
+	mask := image.NewGray(input.Rect)
+
+	// Same seed, same waves
+	_, first := patterns.LoadWavy(input, mask, 42)
+	_, second := patterns.LoadWavy(input, mask, 42)
+	firstPaths, secondPaths := first.([][]int), second.([][]int)
+	if len(firstPaths) != len(secondPaths) {
+		t.Fatalf("same seed gave %d and %d seams", len(firstPaths), len(secondPaths))
+	}
+	seen := make(map[int]bool)
+	for i, path := range firstPaths {
+		for j, index := range path {
+			if secondPaths[i][j] != index {
+				t.Errorf("same seed gave different pixels at %d of seam %d", j, i)
+			}
+			if seen[index] {
+				t.Errorf("pixel %d is in more than one seam", index)
+			}
+			seen[index] = true
+			// At most one pixel per column, left to right. Seams near the
+			// edges skip columns where they swing off the image
+			if j > 0 && index%WIDTH <= path[j-1]%WIDTH {
+				t.Errorf("seam %d goes back from column %d to %d", i, path[j-1]%WIDTH, index%WIDTH)
+			}
+		}
+	}
+	if len(seen) != WIDTH*HEIGHT {
+		t.Errorf("seams cover %d pixels, expected %d", len(seen), WIDTH*HEIGHT)
+	}
+}
+func TestLoadColumn(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+
+	expected := [][]color.RGBA{
+		{input.RGBAAt(0, 0), input.RGBAAt(0, 1), input.RGBAAt(0, 2)},
+		{input.RGBAAt(1, 0), input.RGBAAt(1, 1), input.RGBAAt(1, 2)},
+		{input.RGBAAt(2, 0), input.RGBAAt(2, 1), input.RGBAAt(2, 2)},
+	}
+	actual, _ := patterns.LoadColumn(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestLoadDiagonal(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+
+	// Bottom-left corner first, each diagonal read top-left -> bottom-right
+	expected := [][]color.RGBA{
+		{input.RGBAAt(0, 2)},
+		{input.RGBAAt(0, 1), input.RGBAAt(1, 2)},
+		{input.RGBAAt(0, 0), input.RGBAAt(1, 1), input.RGBAAt(2, 2)},
+		{input.RGBAAt(1, 0), input.RGBAAt(2, 1)},
+		{input.RGBAAt(2, 0)},
+	}
+	actual, _ := patterns.LoadDiagonal(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestLoadAntidiagonal(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+
+	// Top-left corner first, each antidiagonal read top-right -> bottom-left
+	expected := [][]color.RGBA{
+		{input.RGBAAt(0, 0)},
+		{input.RGBAAt(1, 0), input.RGBAAt(0, 1)},
+		{input.RGBAAt(2, 0), input.RGBAAt(1, 1), input.RGBAAt(0, 2)},
+		{input.RGBAAt(2, 1), input.RGBAAt(1, 2)},
+		{input.RGBAAt(2, 2)},
+	}
+	actual, _ := patterns.LoadAntidiagonal(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestLoadLine(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+	withConfig(t, func() { shared.Config.Angle = 0 })
+
+	// 0° should walk rows, 90° should walk columns
+	expected := [][]color.RGBA{
+		{input.RGBAAt(0, 0), input.RGBAAt(1, 0), input.RGBAAt(2, 0)},
+		{input.RGBAAt(0, 1), input.RGBAAt(1, 1), input.RGBAAt(2, 1)},
+		{input.RGBAAt(0, 2), input.RGBAAt(1, 2), input.RGBAAt(2, 2)},
+	}
+	actual, _ := patterns.LoadLine(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+
+	shared.Config.Angle = 90
+	expected = [][]color.RGBA{
+		{input.RGBAAt(0, 0), input.RGBAAt(0, 1), input.RGBAAt(0, 2)},
+		{input.RGBAAt(1, 0), input.RGBAAt(1, 1), input.RGBAAt(1, 2)},
+		{input.RGBAAt(2, 0), input.RGBAAt(2, 1), input.RGBAAt(2, 2)},
+	}
+	actual, _ = patterns.LoadLine(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestSaveLineAngles(t *testing.T) {
+	withConfig(t, func() {})
+	for _, angle := range []float64{0, 30, 45, 90, 135, 200, -60} {
+		shared.Config.Angle = angle
+		input := genTestPic(5, 8, t)
+		mask := image.NewGray(input.Rect)
+
+		loaded, extra := patterns.LoadLine(input, mask, 0)
+		res := patterns.SaveLine(image.NewRGBA(input.Rect), loaded, input.Rect, extra)
+		compareSaveEquality(input, res, t)
+	}
+}
 func TestLoadSpiral(t *testing.T) {
 	// Constants are not to be modified; test expects 3 and 3
 	DIMS := 3
@@ -45,14 +168,606 @@ func TestLoadSpiral(t *testing.T) {
 			input.RGBAAt(1, 1),
 		},
 	}
-	actual, _ := patterns.LoadSpiral(input, mask)
+	actual, _ := patterns.LoadSpiral(input, mask, 0)
 	// Compare equality of each element in each slice
 	compareLoadEquality(input, expected, actual, t)
 }
+func TestSpiralOptions(t *testing.T) {
+	WIDTH, HEIGHT := 5, 3
+	withConfig(t, func() {})
+
+	// First two pixels for each start corner and direction
+	cases := []struct {
+		start, direction string
+		first, second    [2]int
+	}{
+		{"tl", "cw", [2]int{0, 0}, [2]int{1, 0}},
+		{"tr", "cw", [2]int{4, 0}, [2]int{4, 1}},
+		{"br", "cw", [2]int{4, 2}, [2]int{3, 2}},
+		{"bl", "cw", [2]int{0, 2}, [2]int{0, 1}},
+		{"tl", "ccw", [2]int{0, 0}, [2]int{0, 1}},
+		{"tr", "ccw", [2]int{4, 0}, [2]int{3, 0}},
+		{"br", "ccw", [2]int{4, 2}, [2]int{4, 1}},
+		{"bl", "ccw", [2]int{0, 2}, [2]int{1, 2}},
+	}
+	for _, c := range cases {
+		shared.Config.Spiral = types.SpiralConfig{Direction: c.direction, Start: c.start, Continuous: true}
+		input := genTestPic(WIDTH, HEIGHT, t)
+		_, extra := patterns.LoadSpiral(input, image.NewGray(input.Rect), 0)
+		paths := extra.([][]int)
+		if len(paths) != 1 {
+			t.Errorf("%s %s: expected 1 continuous seam, got %d", c.start, c.direction, len(paths))
+			continue
+		}
+		path := paths[0]
+		if path[0] != c.first[1]*WIDTH+c.first[0] || path[1] != c.second[1]*WIDTH+c.second[0] {
+			t.Errorf("%s %s: starts with pixels %d, %d", c.start, c.direction, path[0], path[1])
+		}
+
+		// One unbroken walk over every pixel
+		if len(path) != WIDTH*HEIGHT {
+			t.Errorf("%s %s: covers %d pixels, expected %d", c.start, c.direction, len(path), WIDTH*HEIGHT)
+		}
+		seen := make(map[int]bool)
+		for i, index := range path {
+			if seen[index] {
+				t.Errorf("%s %s: visits pixel %d twice", c.start, c.direction, index)
+			}
+			seen[index] = true
+			if i > 0 {
+				dx := index%WIDTH - path[i-1]%WIDTH
+				dy := index/WIDTH - path[i-1]/WIDTH
+				if max(dx, -dx)+max(dy, -dy) != 1 {
+					t.Errorf("%s %s: jumps from pixel %d to %d", c.start, c.direction, path[i-1], index)
+				}
+			}
+		}
+	}
+
+	// Outward ends where inward starts
+	shared.Config.Spiral = types.SpiralConfig{Outward: true}
+	input := genTestPic(WIDTH, HEIGHT, t)
+	_, extra := patterns.LoadSpiral(input, image.NewGray(input.Rect), 0)
+	paths := extra.([][]int)
+	last := paths[len(paths)-1]
+	if last[len(last)-1] != 0 {
+		t.Errorf("outward spiral ends at pixel %d, expected 0", last[len(last)-1])
+	}
+}
+func TestLoadRings(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+	withConfig(t, func() {
+		shared.Config.Center = types.CenterConfig{X: 0.5, Y: 0.5}
+	})
+
+	// Center pixel first, then the ring around it clockwise from 3 o'clock
+	expected := [][]color.RGBA{
+		{input.RGBAAt(1, 1)},
+		{
+			input.RGBAAt(2, 1), input.RGBAAt(2, 2), input.RGBAAt(1, 2), input.RGBAAt(0, 2),
+			input.RGBAAt(0, 1), input.RGBAAt(0, 0), input.RGBAAt(1, 0), input.RGBAAt(2, 0),
+		},
+	}
+	actual, _ := patterns.LoadRings(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestSaveRadialCenters(t *testing.T) {
+	withConfig(t, func() {})
+	for _, center := range []types.CenterConfig{{X: 0.5, Y: 0.5}, {X: 0.1, Y: 0.8}, {X: 1, Y: 0}} {
+		shared.Config.Center = center
+		input := genTestPic(9, 6, t)
+		mask := image.NewGray(input.Rect)
+
+		loaded, extra := patterns.LoadRings(input, mask, 0)
+		res := patterns.SaveRings(image.NewRGBA(input.Rect), loaded, input.Rect, extra)
+		compareSaveEquality(input, res, t)
+
+		loaded, extra = patterns.LoadRays(input, mask, 0)
+		res = patterns.SaveRays(image.NewRGBA(input.Rect), loaded, input.Rect, extra)
+		compareSaveEquality(input, res, t)
+	}
+}
+func TestLoadPolar(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+	withConfig(t, func() {
+		shared.Config.Center = types.CenterConfig{X: 0.5, Y: 0.5}
+		shared.Config.Polar = types.PolarConfig{Scan: "radial", Angles: 4, RadiusStep: 1}
+	})
+
+	// 4 wedges clockwise from 3 o'clock, each walked from the center out
+	expected := [][]color.RGBA{
+		{input.RGBAAt(1, 1), input.RGBAAt(2, 1), input.RGBAAt(2, 2)},
+		{input.RGBAAt(1, 2), input.RGBAAt(0, 2)},
+		{input.RGBAAt(0, 1), input.RGBAAt(0, 0)},
+		{input.RGBAAt(1, 0), input.RGBAAt(2, 0)},
+	}
+	actual, _ := patterns.LoadPolar(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+
+	// Angular scanlines with the default grid are just rings
+	shared.Config.Polar = types.PolarConfig{Scan: "angular"}
+	_, polar := patterns.LoadPolar(input, mask, 0)
+	_, rings := patterns.LoadRings(input, mask, 0)
+	if len(polar.([][]int)) != len(rings.([][]int)) {
+		t.Errorf("angular polar gave %d scanlines, rings gave %d", len(polar.([][]int)), len(rings.([][]int)))
+	}
+
+	// Coarse grids still cover every pixel exactly once
+	shared.Config.Polar = types.PolarConfig{Scan: "angular", Angles: 5, RadiusStep: 3}
+	wide := genTestPic(13, 7, t)
+	loaded, extra := patterns.LoadPolar(wide, image.NewGray(wide.Rect), 0)
+	res := patterns.SavePolar(image.NewRGBA(wide.Rect), loaded, wide.Rect, extra)
+	compareSaveEquality(wide, res, t)
+}
 func TestLoadSeam(t *testing.T) {
 
 }
 
+func TestLoadCarve(t *testing.T) {
+	WIDTH, HEIGHT := 7, 5
+	input := genTestPic(WIDTH, HEIGHT, t)
+	mask := image.NewGray(input.Rect)
+	withConfig(t, func() {
+		shared.Config.SeamCount = 4
+	})
+
+	actual, extra := patterns.LoadCarve(input, mask, 0)
+	paths := extra.([][]int)
+	if len(*actual) != 4 {
+		t.Fatalf("expected 4 seams, got %d", len(*actual))
+	}
+	// Seams run top to bottom and never overlap. Only the first seam is
+	// guaranteed to be connected, later ones can hop over removed pixels
+	seen := make(map[int]bool)
+	for i, path := range paths {
+		if len(path) != HEIGHT {
+			t.Errorf("seam %d is %d pixels long, expected %d", i, len(path), HEIGHT)
+		}
+		for y, index := range path {
+			if index/WIDTH != y {
+				t.Errorf("pixel %d of seam %d is on row %d", y, i, index/WIDTH)
+			}
+			if i == 0 && y > 0 && (index%WIDTH-path[y-1]%WIDTH > 1 || path[y-1]%WIDTH-index%WIDTH > 1) {
+				t.Errorf("seam %d jumps between rows %d and %d", i, y-1, y)
+			}
+			if seen[index] {
+				t.Errorf("pixel %d is in more than one seam", index)
+			}
+			seen[index] = true
+			if (*actual)[i][y].ToColor() != input.RGBAAt(index%WIDTH, y) {
+				t.Errorf("pixel %d of seam %d doesn't match its source", y, i)
+			}
+		}
+	}
+}
+func TestCarveAvoidsEdges(t *testing.T) {
+	WIDTH, HEIGHT := 8, 6
+	withConfig(t, func() {
+		shared.Config.SeamCount = 1
+	})
+
+	// A gentle ramp with a step halfway across. The cheapest seam stays on
+	// the ramp, clear of the step
+	input := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			value := uint8(100 + 2*x)
+			if x >= WIDTH/2 {
+				value += 82
+			}
+			input.SetRGBA(x, y, color.RGBA{R: value, G: value, B: value, A: 255})
+		}
+	}
+	_, extra := patterns.LoadCarve(input, image.NewGray(input.Rect), 0)
+	for _, index := range extra.([][]int)[0] {
+		if x := index % WIDTH; x == WIDTH/2-1 || x == WIDTH/2 {
+			t.Errorf("seam runs through the edge at pixel %d", index)
+		}
+	}
+}
+func TestLoadCarveHorizontal(t *testing.T) {
+	WIDTH, HEIGHT := 7, 5
+	input := genTestPic(WIDTH, HEIGHT, t)
+	mask := image.NewGray(input.Rect)
+
+	// Every row gets carved, each seam runs left to right. seamh is the same pattern
+	actual, extra := patterns.Loader["seamhload"](input, mask, 0)
+	paths := extra.([][]int)
+	if len(*actual) != HEIGHT {
+		t.Fatalf("expected %d seams, got %d", HEIGHT, len(*actual))
+	}
+	for i, path := range paths {
+		if len(path) != WIDTH {
+			t.Errorf("seam %d is %d pixels long, expected %d", i, len(path), WIDTH)
+		}
+		for x, index := range path {
+			if index%WIDTH != x {
+				t.Errorf("pixel %d of seam %d is in column %d", x, i, index%WIDTH)
+			}
+		}
+	}
+}
+func TestLoadSnake(t *testing.T) {
+	DIMS := 3
+	input := genTestPic(DIMS, DIMS, t)
+	mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
+
+	// One seam, every other row backwards
+	expected := [][]color.RGBA{
+		{
+			input.RGBAAt(0, 0), input.RGBAAt(1, 0), input.RGBAAt(2, 0),
+			input.RGBAAt(2, 1), input.RGBAAt(1, 1), input.RGBAAt(0, 1),
+			input.RGBAAt(0, 2), input.RGBAAt(1, 2), input.RGBAAt(2, 2),
+		},
+	}
+	actual, _ := patterns.LoadSnake(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestCurvesAreContinuous(t *testing.T) {
+	// Hilbert should never jump, even on awkward dimensions (it may step
+	// diagonally once on odd sizes), and uncropped peano never jumps at all
+	cases := []struct {
+		name           string
+		load           func(*image.RGBA, *image.Gray, int64) (*[][]types.PixelWithMask, any)
+		width, height  int
+		allowDiagonals bool
+	}{
+		{"hilbert", patterns.LoadHilbert, 16, 16, false},
+		{"hilbert", patterns.LoadHilbert, 10, 6, false},
+		{"hilbert", patterns.LoadHilbert, 7, 13, true},
+		{"peano", patterns.LoadPeano, 9, 9, false},
+		{"peano", patterns.LoadPeano, 27, 27, false},
+	}
+	for _, c := range cases {
+		input := genTestPic(c.width, c.height, t)
+		_, extra := c.load(input, image.NewGray(input.Rect), 0)
+		paths := extra.([][]int)
+		if len(paths) != 1 || len(paths[0]) != c.width*c.height {
+			t.Errorf("%s %dx%d: expected 1 seam covering the image", c.name, c.width, c.height)
+			continue
+		}
+		path := paths[0]
+		for i := 1; i < len(path); i++ {
+			dx := path[i]%c.width - path[i-1]%c.width
+			dy := path[i]/c.width - path[i-1]/c.width
+			steps := max(dx, -dx) + max(dy, -dy)
+			if steps != 1 && !(c.allowDiagonals && steps == 2 && dx != 0 && dy != 0) {
+				t.Errorf("%s %dx%d: jumps from pixel %d to %d", c.name, c.width, c.height, path[i-1], path[i])
+			}
+		}
+	}
+}
+func TestLoadFlow(t *testing.T) {
+	WIDTH, HEIGHT := 12, 9
+	withConfig(t, func() {})
+	for _, alongGradient := range []bool{false, true} {
+		shared.Config.FlowGradient = alongGradient
+		input := genTestPic(WIDTH, HEIGHT, t)
+		_, extra := patterns.LoadFlow(input, image.NewGray(input.Rect), 0)
+
+		// Streamlines step between neighbors and never share pixels
+		seen := make(map[int]bool)
+		for _, path := range extra.([][]int) {
+			for i, index := range path {
+				if seen[index] {
+					t.Errorf("pixel %d is on more than one streamline", index)
+				}
+				seen[index] = true
+				if i == 0 {
+					continue
+				}
+				dx := index%WIDTH - path[i-1]%WIDTH
+				dy := index/WIDTH - path[i-1]/WIDTH
+				if max(dx, -dx) > 1 || max(dy, -dy) > 1 {
+					t.Errorf("streamline jumps from pixel %d to %d", path[i-1], index)
+				}
+			}
+		}
+		if len(seen) != WIDTH*HEIGHT {
+			t.Errorf("streamlines cover %d pixels, expected %d", len(seen), WIDTH*HEIGHT)
+		}
+	}
+}
+func TestLoadRegions(t *testing.T) {
+	WIDTH, HEIGHT := 20, 12
+	withConfig(t, func() {
+		shared.Config.Cells = 6
+	})
+	loaders := map[string]func(*image.RGBA, *image.Gray, int64) (*[][]types.PixelWithMask, any){
+		"voronoi":    patterns.LoadVoronoi,
+		"superpixel": patterns.LoadSuperpixel,
+	}
+	for name, load := range loaders {
+		for _, scan := range []string{"row", "spiral"} {
+			shared.Config.RegionScan = scan
+			input := genTestPic(WIDTH, HEIGHT, t)
+			_, extra := load(input, image.NewGray(input.Rect), 7)
+			paths := extra.([][]int)
+			if len(paths) < 2 || len(paths) > 12 {
+				t.Errorf("%s/%s: expected about 6 regions, got %d", name, scan, len(paths))
+			}
+
+			seen := make(map[int]bool)
+			for _, path := range paths {
+				for i, index := range path {
+					if seen[index] {
+						t.Errorf("%s/%s: pixel %d is in more than one region", name, scan, index)
+					}
+					seen[index] = true
+					// Row scans keep pixels in reading order
+					if scan == "row" && i > 0 && index < path[i-1] {
+						t.Errorf("%s/%s: region isn't in row order", name, scan)
+					}
+				}
+			}
+			if len(seen) != WIDTH*HEIGHT {
+				t.Errorf("%s/%s: regions cover %d pixels, expected %d", name, scan, len(seen), WIDTH*HEIGHT)
+			}
+		}
+	}
+}
+func TestLoadTiles(t *testing.T) {
+	WIDTH, HEIGHT := 4, 4
+	input := genTestPic(WIDTH, HEIGHT, t)
+	mask := image.NewGray(input.Rect)
+	withConfig(t, func() {
+		shared.Config.Tiles = types.GridConfig{X: 2, Y: 1}
+		shared.Config.TileMode = "checkerboard"
+	})
+
+	// Left tile is rows, right tile is columns
+	expected := [][]color.RGBA{
+		{input.RGBAAt(0, 0), input.RGBAAt(1, 0)},
+		{input.RGBAAt(0, 1), input.RGBAAt(1, 1)},
+		{input.RGBAAt(0, 2), input.RGBAAt(1, 2)},
+		{input.RGBAAt(0, 3), input.RGBAAt(1, 3)},
+		{input.RGBAAt(2, 0), input.RGBAAt(2, 1), input.RGBAAt(2, 2), input.RGBAAt(2, 3)},
+		{input.RGBAAt(3, 0), input.RGBAAt(3, 1), input.RGBAAt(3, 2), input.RGBAAt(3, 3)},
+	}
+	actual, _ := patterns.LoadTiles(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+}
+func TestSaveTilesRandom(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Tiles = types.GridConfig{X: 3, Y: 4}
+		shared.Config.TileMode = "random"
+	})
+	for seed := int64(1); seed <= 5; seed++ {
+		input := genTestPic(11, 9, t)
+		loaded, extra := patterns.LoadTiles(input, image.NewGray(input.Rect), seed)
+		res := patterns.SaveTiles(image.NewRGBA(input.Rect), loaded, input.Rect, extra)
+		compareSaveEquality(input, res, t)
+	}
+}
+func TestLoadQuadtree(t *testing.T) {
+	WIDTH, HEIGHT := 8, 8
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Quadtree = types.QuadtreeConfig{Variance: 10, MinSize: 2}
+	})
+
+	// Flat except for noise in the top left quadrant
+	input := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	noise := genTestPic(WIDTH, HEIGHT, t)
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			input.SetRGBA(x, y, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+			if x < 4 && y < 4 {
+				input.SetRGBA(x, y, noise.RGBAAt(x, y))
+			}
+		}
+	}
+	// Thresholds only matter for sorting, blocks split the same with any of them
+	for _, thresholds := range []types.ThresholdConfig{{Lower: 0, Upper: 1}, {Lower: 0.1, Upper: 0.9}, {Lower: 0.5, Upper: 1}} {
+		shared.Config.Thresholds = thresholds
+		actual, extra := patterns.LoadQuadtree(input, image.NewGray(input.Rect), 0)
+		paths := extra.([][]int)
+		// 4 little blocks in the noisy quadrant, 3 big flat ones
+		if len(paths) != 7 {
+			t.Fatalf("thresholds %v: expected 7 blocks, got %d", thresholds, len(paths))
+		}
+		for i, path := range paths {
+			size := 4
+			if i >= 4 {
+				size = 16
+			}
+			if len(path) != size {
+				t.Errorf("thresholds %v: block %d has %d pixels, expected %d", thresholds, i, len(path), size)
+			}
+		}
+		res := patterns.SaveQuadtree(image.NewRGBA(input.Rect), actual, input.Rect, extra)
+		compareSaveEquality(input, res, t)
+	}
+}
+func TestLoadContour(t *testing.T) {
+	WIDTH, HEIGHT := 6, 6
+	withConfig(t, func() {
+		shared.Config.Contour = types.ContourConfig{Source: "lightness", Level: 0.5, Width: 1}
+	})
+
+	// Bright square in the middle, the contour runs around its edge
+	input := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			input.SetRGBA(x, y, color.RGBA{A: 255})
+			if x >= 2 && x < 4 && y >= 2 && y < 4 {
+				input.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	actual, extra := patterns.LoadContour(input, image.NewGray(input.Rect), 0)
+	paths := extra.([][]int)
+	if len(paths) != 2 {
+		t.Fatalf("expected 1 band and the leftovers, got %d seams", len(paths))
+	}
+	// The band is the square plus the ring around it
+	if len(paths[0]) != 16 {
+		t.Errorf("band has %d pixels, expected 16", len(paths[0]))
+	}
+	for _, pixel := range (*actual)[0] {
+		if pixel.Mask != 0 {
+			t.Errorf("band pixel is masked")
+		}
+	}
+	// Everything else is masked off
+	if len(paths[1]) != WIDTH*HEIGHT-16 {
+		t.Errorf("leftovers have %d pixels, expected %d", len(paths[1]), WIDTH*HEIGHT-16)
+	}
+	for _, pixel := range (*actual)[1] {
+		if pixel.Mask != 255 {
+			t.Errorf("leftover pixel isn't masked")
+		}
+	}
+}
+func TestLoadPath(t *testing.T) {
+	WIDTH, HEIGHT := 8, 5
+	input := genTestPic(WIDTH, HEIGHT, t)
+	mask := image.NewGray(input.Rect)
+	withConfig(t, func() {
+		shared.Config.Polylines = []types.Polyline{{{0.5, 2.5}, {7.5, 2.5}}}
+		shared.Config.StrokeWidth = 1
+	})
+
+	// One pixel thick stroke straight along the middle row
+	expected := [][]color.RGBA{{
+		input.RGBAAt(0, 2), input.RGBAAt(1, 2), input.RGBAAt(2, 2), input.RGBAAt(3, 2),
+		input.RGBAAt(4, 2), input.RGBAAt(5, 2), input.RGBAAt(6, 2), input.RGBAAt(7, 2),
+	}}
+	actual, _ := patterns.LoadPath(input, mask, 0)
+	compareLoadEquality(input, expected, actual, t)
+	// Everything else is masked off
+	if len((*actual)[1]) != WIDTH*(HEIGHT-1) {
+		t.Errorf("expected %d skipped pixels, got %d", WIDTH*(HEIGHT-1), len((*actual)[1]))
+	}
+	for _, pixel := range (*actual)[1] {
+		if pixel.Mask != 255 {
+			t.Errorf("skipped pixel isn't masked")
+		}
+	}
+}
+func TestReadPolylines(t *testing.T) {
+	dir := t.TempDir()
+	svg := `<svg xmlns="http://www.w3.org/2000/svg">
+		<path d="M1,2 L3 4 h2 v-1 Z m1 1 c 1 1 2 2 3 3 M9,9"/>
+		<polyline points="0,0 5,5 10-2"/>
+		<polyline points="7,7"/>
+	</svg>`
+	if err := os.WriteFile(filepath.Join(dir, "paths.svg"), []byte(svg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	polylines, err := patterns.ReadPolylines(filepath.Join(dir, "paths.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each subpath is its own polyline, lone points are dropped
+	if len(polylines) != 3 {
+		t.Fatalf("expected 3 polylines, got %d", len(polylines))
+	}
+	expected := types.Polyline{{1, 2}, {3, 4}, {5, 4}, {5, 3}, {1, 2}}
+	for i, point := range expected {
+		if polylines[0][i] != point {
+			t.Errorf("point %d of the first subpath is %v, expected %v", i, polylines[0][i], point)
+		}
+	}
+	// Relative move from the closed start, then a flattened curve ending at (5, 6)
+	if polylines[1][0] != [2]float64{2, 3} || polylines[1][len(polylines[1])-1] != [2]float64{5, 6} {
+		t.Errorf("second subpath runs %v to %v", polylines[1][0], polylines[1][len(polylines[1])-1])
+	}
+	if len(polylines[2]) != 3 || polylines[2][2] != [2]float64{10, -2} {
+		t.Errorf("polyline parsed as %v", polylines[2])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "paths.json"), []byte(`[[[0, 0], [4, 4]], [[2, 2]]]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	polylines, err = patterns.ReadPolylines(filepath.Join(dir, "paths.json"))
+	if err != nil || len(polylines) != 1 || polylines[0][1] != [2]float64{4, 4} {
+		t.Errorf("json parsed as %v (%v), expected the lone point to be dropped", polylines, err)
+	}
+}
+func TestEdgeMagnitude(t *testing.T) {
+	WIDTH, HEIGHT := 8, 4
+	// Dark left half, flat bright right half
+	input := image.NewRGBA(image.Rect(0, 0, WIDTH, HEIGHT))
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			input.SetRGBA(x, y, color.RGBA{A: 255})
+			if x >= WIDTH/2 {
+				input.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	edges := patterns.EdgeMagnitude(input)
+	for y := 0; y < HEIGHT; y++ {
+		for x := 0; x < WIDTH; x++ {
+			value := edges.GrayAt(x, y).Y
+			// Only the two columns either side of the step are edges, the
+			// borders don't wrap around onto the next row or get left out
+			if x == WIDTH/2-1 || x == WIDTH/2 {
+				if value != edges.GrayAt(WIDTH/2-1, 1).Y || value < 128 {
+					t.Errorf("pixel (%d,%d) has edge %d, expected the same strong edge down the step", x, y, value)
+				}
+			} else if value != 0 {
+				t.Errorf("flat pixel (%d,%d) has edge %d", x, y, value)
+			}
+		}
+	}
+
+	// Same thing turned on its side
+	input = image.NewRGBA(image.Rect(0, 0, HEIGHT, WIDTH))
+	for y := 0; y < WIDTH; y++ {
+		for x := 0; x < HEIGHT; x++ {
+			input.SetRGBA(x, y, color.RGBA{A: 255})
+			if y >= WIDTH/2 {
+				input.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	edges = patterns.EdgeMagnitude(input)
+	for y := 0; y < WIDTH; y++ {
+		for x := 0; x < HEIGHT; x++ {
+			value := edges.GrayAt(x, y).Y
+			if (y == WIDTH/2-1 || y == WIDTH/2) != (value >= 128) {
+				t.Errorf("pixel (%d,%d) has edge %d", x, y, value)
+			}
+		}
+	}
+}
+func TestSeamPaths(t *testing.T) {
+	WIDTH, HEIGHT := 6, 4
+	for key, load := range patterns.Loader {
+		pattern := key[:len(key)-4]
+		input := genTestPic(WIDTH, HEIGHT, t)
+		seams, data := load(input, image.NewGray(input.Rect), 0)
+		paths := patterns.SeamPaths(pattern, seams, input.Rect, data)
+		if len(paths) != len(*seams) {
+			t.Fatalf("%s: %d paths for %d seams", pattern, len(paths), len(*seams))
+		}
+		// Every loaded pixel maps back to where it came from. seam pads
+		// with nil pixels and wraps around, so it can come up short
+		for i, seam := range *seams {
+			if len(paths[i]) != len(seam) {
+				t.Fatalf("%s: path %d is %d long, seam is %d", pattern, i, len(paths[i]), len(seam))
+			}
+			for j, pixel := range seam {
+				index := paths[i][j]
+				if index < 0 {
+					if pattern != "seam" {
+						t.Errorf("%s: pixel %d of seam %d doesn't map anywhere", pattern, j, i)
+					}
+					continue
+				}
+				if pixel.ToColor() != input.RGBAAt(index%WIDTH, index/WIDTH) {
+					t.Errorf("%s: pixel %d of seam %d doesn't match the input at %d", pattern, j, i, index)
+				}
+			}
+		}
+	}
+}
 func TestSaves(t *testing.T) {
 	DIMS := 3
 	for key := range patterns.Saver {
@@ -60,12 +775,83 @@ func TestSaves(t *testing.T) {
 		input := genTestPic(DIMS, DIMS, t)
 		mask := image.NewGray(image.Rect(0, 0, DIMS, DIMS))
 
-		loaded, extra := patterns.Loader[key[:len(key)-4]+"load"](input, mask)
+		loaded, extra := patterns.Loader[key[:len(key)-4]+"load"](input, mask, 0)
 		res := patterns.Saver[key](image.NewRGBA(image.Rect(0, 0, DIMS, DIMS)), loaded, input.Rect, extra)
 		compareSaveEquality(input, res, t)
 	}
 }
 
+func TestBlendMask(t *testing.T) {
+	sorted := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	original := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	mask := image.NewGray(image.Rect(0, 0, 3, 1))
+	for x := 0; x < 3; x++ {
+		sorted.SetRGBA(x, 0, color.RGBA{200, 0, 100, 255})
+		original.SetRGBA(x, 0, color.RGBA{0, 200, 100, 255})
+	}
+	mask.Pix = []uint8{0, 255, 128}
+
+	patterns.BlendMask(sorted, original, mask)
+	expected := []color.RGBA{
+		{200, 0, 100, 255},
+		{0, 200, 100, 255},
+		{100, 100, 100, 255},
+	}
+	for x, want := range expected {
+		if got := sorted.RGBAAt(x, 0); got != want {
+			t.Errorf("pixel %d: got %v, expected %v", x, got, want)
+		}
+	}
+}
+
+func TestBlendLoadMask(t *testing.T) {
+	withConfig(t, func() {
+		shared.Config.Comparator = "lightness"
+		shared.Config.Thresholds = types.ThresholdConfig{Lower: 0, Upper: 1}
+		shared.Config.SectionLength = 4
+	})
+
+	// Descending row, left half unmasked, right half white
+	input := image.NewRGBA(image.Rect(0, 0, 8, 1))
+	mask := image.NewGray(input.Rect)
+	for x := 0; x < 8; x++ {
+		v := uint8(200 - x*20)
+		input.SetRGBA(x, 0, color.RGBA{v, v, v, 255})
+		if x >= 4 {
+			mask.Pix[x] = 255
+		}
+	}
+	sortRow := func(interval string) []uint8 {
+		shared.Config.Interval = interval
+		seams, _ := patterns.LoadRow(input, patterns.BlendLoadMask(mask), 0)
+		state := intervals.NewState(1)
+		state.SetSeam(0)
+		intervals.Sort((*seams)[0], state)
+		values := make([]uint8, 0, 8)
+		for _, pixel := range (*seams)[0] {
+			values = append(values, pixel.R)
+		}
+		return values
+	}
+
+	// Nothing gets skipped, the blend puts the white half back later
+	if values := sortRow("none"); !slices.Equal(values, []uint8{60, 80, 100, 120, 140, 160, 180, 200}) {
+		t.Errorf("none sorted to %v, expected everything sorted", values)
+	}
+	// softmask still sees the mask and leaves the white half alone
+	if values := sortRow("softmask"); !slices.Equal(values, []uint8{140, 160, 180, 200, 120, 100, 80, 60}) {
+		t.Errorf("softmask sorted to %v, expected only the unmasked half sorted", values)
+	}
+}
+
+// saves the whole config, lets set change it, and puts it back after the test
+func withConfig(t *testing.T, set func()) {
+	saved := shared.Config
+	t.Cleanup(func() {
+		shared.Config = saved
+	})
+	set()
+}
 func genTestPic(w, h int, t *testing.T) *image.RGBA {
 	input := image.NewRGBA(image.Rect(0, 0, w, h))
 	// Fill input with random pixels
@@ -0,0 +1,100 @@
+package patterns
+
+import (
+	"image"
+
+	"pixorder/comparators"
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// splits the image into quadrants until every block's variance is under
+// shared.Config.Quadtree.Variance, then loads each block as one seam
+//
+// variance is measured with the active comparator, so flat areas end up as
+// big blocks and busy ones get chopped up small
+func LoadQuadtree(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	values := comparatorValues(img)
+	paths := quadtreePaths(values, dims.X, dims.Y, shared.Config.Quadtree.Variance, shared.Config.Quadtree.MinSize)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveQuadtree(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// each pixel's value under the active comparator, thresholds dont
+// matter here since every pixel still ends up in some block
+func comparatorValues(img *image.RGBA) []float64 {
+	value := comparators.ValueFunctionMappings[shared.Config.Comparator]
+	if value == nil {
+		value = comparators.ValueFunctionMappings["lightness"]
+	}
+
+	dims := img.Bounds().Max
+	values := make([]float64, dims.X*dims.Y)
+	for index := range values {
+		rawPix := img.Pix[index*4 : index*4+4]
+		pixel := types.PixelWithMask{R: rawPix[0], G: rawPix[1], B: rawPix[2], A: rawPix[3]}
+		values[index] = float64(value(pixel))
+	}
+	return values
+}
+
+func quadtreePaths(values []float64, width, height int, maxVariance float64, minSize int) [][]int {
+	minSize = max(1, minSize)
+
+	/// summed-area tables, so any block's variance is a handful of lookups
+	stride := width + 1
+	sums := make([]float64, stride*(height+1))
+	squares := make([]float64, stride*(height+1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := values[y*width+x]
+			at := (y+1)*stride + x + 1
+			sums[at] = value + sums[at-1] + sums[at-stride] - sums[at-stride-1]
+			squares[at] = value*value + squares[at-1] + squares[at-stride] - squares[at-stride-1]
+		}
+	}
+	blockSum := func(table []float64, rect image.Rectangle) float64 {
+		return table[rect.Max.Y*stride+rect.Max.X] - table[rect.Min.Y*stride+rect.Max.X] -
+			table[rect.Max.Y*stride+rect.Min.X] + table[rect.Min.Y*stride+rect.Min.X]
+	}
+
+	paths := make([][]int, 0)
+	var split func(rect image.Rectangle)
+	split = func(rect image.Rectangle) {
+		if rect.Empty() {
+			return
+		}
+		n := float64(rect.Dx() * rect.Dy())
+		mean := blockSum(sums, rect) / n
+		variance := blockSum(squares, rect)/n - mean*mean
+
+		canSplitX := rect.Dx() >= 2*minSize
+		canSplitY := rect.Dy() >= 2*minSize
+		if variance <= maxVariance || (!canSplitX && !canSplitY) {
+			/// leaf, the whole block in row order
+			path := make([]int, 0, rect.Dx()*rect.Dy())
+			for _, row := range rectRowPaths(rect, width) {
+				path = append(path, row...)
+			}
+			paths = append(paths, path)
+			return
+		}
+
+		midX, midY := rect.Max.X, rect.Max.Y
+		if canSplitX {
+			midX = rect.Min.X + rect.Dx()/2
+		}
+		if canSplitY {
+			midY = rect.Min.Y + rect.Dy()/2
+		}
+		split(image.Rect(rect.Min.X, rect.Min.Y, midX, midY))
+		split(image.Rect(midX, rect.Min.Y, rect.Max.X, midY))
+		split(image.Rect(rect.Min.X, midY, midX, rect.Max.Y))
+		split(image.Rect(midX, midY, rect.Max.X, rect.Max.Y))
+	}
+	split(image.Rect(0, 0, width, height))
+	return paths
+}
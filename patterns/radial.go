@@ -0,0 +1,130 @@
+package patterns
+
+import (
+	"cmp"
+	"image"
+	"math"
+	"slices"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+/// patterns centered around shared.Config.Center
+
+// unwraps the image into polar space around the center and loads its
+// scanlines, angular (shared.Config.Polar.Scan "angular") or radial
+//
+// shared.Config.Polar.Angles and RadiusStep set how coarse the polar grid is,
+// so coarse grids give wedges and thick bands instead of thin rays and rings
+func LoadPolar(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	config := shared.Config.Polar
+	paths := polarPaths(dims.X, dims.Y, config.Scan == "radial", config.Angles, config.RadiusStep)
+	return loadPaths(img, mask, paths), paths
+}
+func SavePolar(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// loads concentric circles, each walked clockwise from 3 o'clock
+func LoadRings(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := ringPaths(dims.X, dims.Y)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveRings(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// loads rays shooting out from the center to the border
+func LoadRays(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := rayPaths(dims.X, dims.Y)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveRays(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// rings are the angular scanlines of a polar unwrap, one pixel apart
+func ringPaths(width, height int) [][]int {
+	return polarPaths(width, height, false, 0, 1)
+}
+
+// rays are the radial scanlines of a polar unwrap
+//
+// there's about one ray per pixel around the furthest ring, so rays stay
+// one pixel wide all the way out
+func rayPaths(width, height int) [][]int {
+	return polarPaths(width, height, true, 0, 1)
+}
+
+// maps every pixel into a polar image around the center, then returns its
+// radial scanlines (one per angle bin, center out) or its angular ones (one
+// per radius bin, clockwise)
+//
+// pixels are only ever binned, never resampled, so the mapping back is exact
+// and each pixel is in exactly one scanline. 0 angleBins means one bin per
+// pixel around the furthest ring
+func polarPaths(width, height int, radial bool, angleBins int, radiusStep float64) [][]int {
+	dists, angles := polarCoords(width, height)
+	maxDist := float64(slices.Max(dists))
+	if angleBins <= 0 {
+		angleBins = max(1, int(2*math.Pi*maxDist))
+	}
+	radiusStep = math.Max(radiusStep, 1)
+
+	var scanlines [][]int
+	if radial {
+		scanlines = make([][]int, angleBins)
+		for index, angle := range angles {
+			bin := int(float64(angle)/(2*math.Pi)*float64(angleBins)) % angleBins
+			scanlines[bin] = append(scanlines[bin], index)
+		}
+		/// within a bin, walk outwards
+		for _, scanline := range scanlines {
+			slices.SortFunc(scanline, func(a, b int) int {
+				return cmp.Compare(dists[a], dists[b])
+			})
+		}
+	} else {
+		scanlines = make([][]int, int(maxDist/radiusStep+0.5)+1)
+		for index, dist := range dists {
+			bin := int(float64(dist)/radiusStep + 0.5)
+			scanlines[bin] = append(scanlines[bin], index)
+		}
+		/// within a bin, walk around clockwise
+		for _, scanline := range scanlines {
+			slices.SortFunc(scanline, func(a, b int) int {
+				return cmp.Compare(angles[a], angles[b])
+			})
+		}
+	}
+	return slices.DeleteFunc(scanlines, func(scanline []int) bool {
+		return len(scanline) == 0
+	})
+}
+
+// distance and clockwise angle (radians, [0, 2π)) of every pixel from the center
+func polarCoords(width, height int) ([]float32, []float32) {
+	centerX := float64(shared.Config.Center.X) * float64(width)
+	centerY := float64(shared.Config.Center.Y) * float64(height)
+
+	dists := make([]float32, width*height)
+	angles := make([]float32, width*height)
+	for y := 0; y < height; y++ {
+		/// measure from the middle of the pixel
+		dy := float64(y) + 0.5 - centerY
+		for x := 0; x < width; x++ {
+			dx := float64(x) + 0.5 - centerX
+			angle := math.Atan2(dy, dx)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			dists[y*width+x] = float32(math.Hypot(dx, dy))
+			angles[y*width+x] = float32(angle)
+		}
+	}
+	return dists, angles
+}
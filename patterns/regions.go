@@ -0,0 +1,214 @@
+package patterns
+
+import (
+	"cmp"
+	"image"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+/// patterns that chop the image into blobs, each blob is one seam
+/// shared.Config.RegionScan picks how each blob gets walked
+
+// how much superpixels care about staying compact vs matching colors
+const superpixelCompactness = 10
+
+// how many times superpixel centers get refined
+const superpixelIterations = 10
+
+// loads random voronoi cells, roughly shared.Config.Cells of them
+func LoadVoronoi(img *image.RGBA, mask *image.Gray, seed int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	labels, count := voronoiLabels(dims.X, dims.Y, shared.Config.Cells, rand.New(rand.NewPCG(uint64(seed), 0)))
+	paths := regionPaths(labels, count, dims.X, shared.Config.RegionScan)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveVoronoi(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// loads slic-style superpixels, clustered on color and position
+func LoadSuperpixel(img *image.RGBA, mask *image.Gray, _ int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	labels, count := superpixelLabels(img, shared.Config.Cells)
+	paths := regionPaths(labels, count, dims.X, shared.Config.RegionScan)
+	return loadPaths(img, mask, paths), paths
+}
+func SaveSuperpixel(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+// splits the image into a grid of about count cells, close to square
+func cellGrid(width, height, count int) (int, int) {
+	count = max(1, count)
+	cols := max(1, int(math.Round(math.Sqrt(float64(count*width)/float64(max(1, height))))))
+	rows := max(1, int(math.Round(float64(count)/float64(cols))))
+	return min(cols, max(1, width)), min(rows, max(1, height))
+}
+
+// drops one random site in each cell of a grid and gives every pixel the label of its nearest site
+func voronoiLabels(width, height, count int, rng *rand.Rand) ([]int, int) {
+	cols, rows := cellGrid(width, height, count)
+	cellW := float64(width) / float64(cols)
+	cellH := float64(height) / float64(rows)
+
+	sitesX := make([]float64, cols*rows)
+	sitesY := make([]float64, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			sitesX[row*cols+col] = (float64(col) + rng.Float64()) * cellW
+			sitesY[row*cols+col] = (float64(row) + rng.Float64()) * cellH
+		}
+	}
+
+	/// with one site per cell the nearest one is never more than 2 cells away
+	labels := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		row := int(float64(y) / cellH)
+		for x := 0; x < width; x++ {
+			col := int(float64(x) / cellW)
+			best := math.Inf(1)
+			for r := max(0, row-2); r <= min(rows-1, row+2); r++ {
+				for c := max(0, col-2); c <= min(cols-1, col+2); c++ {
+					site := r*cols + c
+					dx, dy := float64(x)+0.5-sitesX[site], float64(y)+0.5-sitesY[site]
+					dist := dx*dx + dy*dy
+					if dist < best {
+						best = dist
+						labels[y*width+x] = site
+					}
+				}
+			}
+		}
+	}
+	return labels, cols * rows
+}
+
+// simple linear iterative clustering, minus the connectivity cleanup
+//
+// https://www.iro.umontreal.ca/~mignotte/IFT6150/Articles/SLIC_Superpixels.pdf
+func superpixelLabels(img *image.RGBA, count int) ([]int, int) {
+	dims := img.Bounds().Max
+	width, height := dims.X, dims.Y
+	cols, rows := cellGrid(width, height, count)
+	cellW := float64(width) / float64(cols)
+	cellH := float64(height) / float64(rows)
+	/// search window, and what spatial distance gets normalized against
+	step := math.Max(cellW, cellH)
+
+	type center struct {
+		x, y, r, g, b float64
+	}
+	centers := make([]center, cols*rows)
+	labels := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			labels[y*width+x] = min(int(float64(y)/cellH), rows-1)*cols + min(int(float64(x)/cellW), cols-1)
+		}
+	}
+	pixelAt := func(index int) (float64, float64, float64) {
+		return float64(img.Pix[index*4]), float64(img.Pix[index*4+1]), float64(img.Pix[index*4+2])
+	}
+
+	dists := make([]float64, width*height)
+	for iteration := 0; iteration <= superpixelIterations; iteration++ {
+		/// move every center to the average of its pixels
+		sums := make([]center, len(centers))
+		counts := make([]int, len(centers))
+		for index, label := range labels {
+			r, g, b := pixelAt(index)
+			sums[label].x += float64(index%width) + 0.5
+			sums[label].y += float64(index/width) + 0.5
+			sums[label].r += r
+			sums[label].g += g
+			sums[label].b += b
+			counts[label]++
+		}
+		for i := range centers {
+			if counts[i] == 0 {
+				continue
+			}
+			n := float64(counts[i])
+			centers[i] = center{sums[i].x / n, sums[i].y / n, sums[i].r / n, sums[i].g / n, sums[i].b / n}
+		}
+		if iteration == superpixelIterations {
+			break
+		}
+
+		/// then hand pixels near each center to whichever is closest
+		for i := range dists {
+			dists[i] = math.Inf(1)
+		}
+		for i, c := range centers {
+			if counts[i] == 0 {
+				continue
+			}
+			for y := max(0, int(c.y-step)); y < min(height, int(c.y+step)+1); y++ {
+				for x := max(0, int(c.x-step)); x < min(width, int(c.x+step)+1); x++ {
+					index := y*width + x
+					r, g, b := pixelAt(index)
+					colorDist := (r-c.r)*(r-c.r) + (g-c.g)*(g-c.g) + (b-c.b)*(b-c.b)
+					dx, dy := float64(x)+0.5-c.x, float64(y)+0.5-c.y
+					spaceDist := dx*dx + dy*dy
+					dist := colorDist + spaceDist/(step*step)*superpixelCompactness*superpixelCompactness
+					if dist < dists[index] {
+						dists[index] = dist
+						labels[index] = i
+					}
+				}
+			}
+		}
+	}
+	return labels, len(centers)
+}
+
+// turns labelled pixels into one path per label, walked in row or spiral order
+func regionPaths(labels []int, count, width int, scan string) [][]int {
+	paths := make([][]int, count)
+	for index, label := range labels {
+		paths[label] = append(paths[label], index)
+	}
+	paths = slices.DeleteFunc(paths, func(path []int) bool {
+		return len(path) == 0
+	})
+	if scan != "spiral" {
+		/// already in row order
+		return paths
+	}
+
+	for _, path := range paths {
+		/// rings around the region's middle, each walked clockwise
+		centerX, centerY := 0.0, 0.0
+		for _, index := range path {
+			centerX += float64(index%width) + 0.5
+			centerY += float64(index/width) + 0.5
+		}
+		centerX /= float64(len(path))
+		centerY /= float64(len(path))
+
+		type spiralPixel struct {
+			index, ring int
+			angle       float64
+		}
+		pixels := make([]spiralPixel, len(path))
+		for i, index := range path {
+			dx := float64(index%width) + 0.5 - centerX
+			dy := float64(index/width) + 0.5 - centerY
+			pixels[i] = spiralPixel{index, int(math.Round(math.Max(math.Abs(dx), math.Abs(dy)))), math.Atan2(dy, dx)}
+		}
+		slices.SortFunc(pixels, func(a, b spiralPixel) int {
+			if a.ring != b.ring {
+				return a.ring - b.ring
+			}
+			return cmp.Compare(a.angle, b.angle)
+		})
+		for i, pixel := range pixels {
+			path[i] = pixel.index
+		}
+	}
+	return paths
+}
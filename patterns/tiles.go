@@ -0,0 +1,111 @@
+package patterns
+
+import (
+	"image"
+	"math/rand/v2"
+
+	"pixorder/shared"
+	"pixorder/types"
+)
+
+// cuts the image into a shared.Config.Tiles grid and loads each tile as
+// rows, columns or a spiral
+//
+// tile_mode "random" picks per tile (seeded), "checkerboard" alternates rows and columns
+func LoadTiles(img *image.RGBA, mask *image.Gray, seed int64) (*[][]types.PixelWithMask, any) {
+	dims := img.Bounds().Max
+	paths := tilePaths(dims.X, dims.Y, shared.Config.Tiles.X, shared.Config.Tiles.Y, shared.Config.TileMode, rand.New(rand.NewPCG(uint64(seed), 0)))
+	return loadPaths(img, mask, paths), paths
+}
+func SaveTiles(outputImg *image.RGBA, seams *[][]types.PixelWithMask, dims image.Rectangle, data ...any) *image.RGBA {
+	return savePaths(outputImg, seams, data[0].([][]int))
+}
+
+func tilePaths(width, height, cols, rows int, mode string, rng *rand.Rand) [][]int {
+	cols = min(max(1, cols), max(1, width))
+	rows = min(max(1, rows), max(1, height))
+	paths := make([][]int, 0)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			tile := image.Rect(col*width/cols, row*height/rows, (col+1)*width/cols, (row+1)*height/rows)
+
+			direction := rng.IntN(3)
+			if mode == "checkerboard" {
+				direction = (row + col) % 2
+			}
+			switch direction {
+			case 0:
+				paths = append(paths, rectRowPaths(tile, width)...)
+			case 1:
+				paths = append(paths, rectColumnPaths(tile, width)...)
+			default:
+				paths = append(paths, rectSpiralPaths(tile, width)...)
+			}
+		}
+	}
+	return paths
+}
+
+/// rect path utils, width is the full image's width
+
+// one path per row of rect
+func rectRowPaths(rect image.Rectangle, width int) [][]int {
+	paths := make([][]int, 0, rect.Dy())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		path := make([]int, 0, rect.Dx())
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			path = append(path, y*width+x)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// one path per column of rect
+func rectColumnPaths(rect image.Rectangle, width int) [][]int {
+	paths := make([][]int, 0, rect.Dx())
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		path := make([]int, 0, rect.Dy())
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			path = append(path, y*width+x)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// one path per t-r-b-l ring of rect, outside in
+//
+// rings that collapse into a single row or column are only walked once,
+// so every pixel gets visited exactly once whatever the dimensions
+func rectSpiralPaths(rect image.Rectangle, width int) [][]int {
+	paths := make([][]int, 0)
+	top, bottom := rect.Min.Y, rect.Max.Y-1
+	left, right := rect.Min.X, rect.Max.X-1
+	for top <= bottom && left <= right {
+		path := make([]int, 0)
+		/// right
+		for x := left; x <= right; x++ {
+			path = append(path, top*width+x)
+		}
+		/// down
+		for y := top + 1; y <= bottom; y++ {
+			path = append(path, y*width+right)
+		}
+		/// left, unless the bottom is the top
+		if top < bottom {
+			for x := right - 1; x >= left; x-- {
+				path = append(path, bottom*width+x)
+			}
+		}
+		/// up, unless the left is the right
+		if left < right {
+			for y := bottom - 1; y > top; y-- {
+				path = append(path, y*width+left)
+			}
+		}
+		paths = append(paths, path)
+		top, bottom, left, right = top+1, bottom-1, left+1, right-1
+	}
+	return paths
+}
@@ -103,7 +103,12 @@ func main() {
 			&cli.StringFlag{
 				Name:    "mask",
 				Aliases: []string{"m"},
-				Usage:   "b&w `mask` to determine which pixels to touch; white is skipped",
+				Usage:   "b&w `mask` to determine which pixels to touch; white is skipped, gray fades the sort out with [softmask]",
+			},
+			&cli.BoolFlag{
+				Name:  "mask_blend",
+				Value: false,
+				Usage: "sort as if unmasked (gray still drives [softmask]), then blend the original back in by the mask (white is all original) for soft edges",
 			},
 			&cli.FloatFlag{
 				Name:    "lower_threshold",
@@ -129,11 +134,335 @@ func main() {
 					return nil
 				},
 			},
+			&cli.StringFlag{
+				Name:  "interval_image",
+				Usage: "b&w `image` for [file], a new stretch starts wherever it flips between black and white",
+			},
+			&cli.FloatFlag{
+				Name:  "edge_threshold",
+				Value: 0.2,
+				Usage: "[edges] splits seams at pixels with an edge strength above this `thresh`old",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 || v > 1.0 {
+						return fmt.Errorf("edge_threshold is outside of range [0.0-1.0]")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "passes",
+				Value: 10,
+				Usage: "[partial] how many `N` rounds of neighbor swaps to do, more gets closer to fully sorted",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 0 {
+						return fmt.Errorf("passes can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "color_distance",
+				Value: 0,
+				Usage: "[distance] splits seams between neighbors further apart than this `dist`ance, on the distance_metric's scale (rgb 0-441, deltae 0-100). 0 uses the metric's default, 40 for rgb or 10 for deltae",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 {
+						return fmt.Errorf("color_distance can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "distance_metric",
+				Value: "rgb",
+				Usage: "how [distance] measures color distance [rgb, deltae]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "rgb" && v != "deltae" {
+						return fmt.Errorf("invalid distance_metric \"%s\" [rgb, deltae]", v)
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "period_amplitude",
+				Value: 0.5,
+				Usage: "[sine, triangle, sawtooth] how far stretch lengths swing, as a `frac`tion of section_length",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 || v > 1.0 {
+						return fmt.Errorf("period_amplitude is outside of range [0.0-1.0]")
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "period_length",
+				Value: 500,
+				Usage: "[sine, triangle, sawtooth] how many `px` along the seam one swing takes",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v <= 0.0 {
+						return fmt.Errorf("period_length has to be positive")
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "period_phase",
+				Value: 5,
+				Usage: "[sine, triangle, sawtooth] how many `px` each seam's swing is shifted from the last",
+			},
 			&cli.FloatFlag{
 				Name:    "angle",
 				Value:   0.0,
 				Aliases: []string{"a"},
-				Usage:   "rotate the image by `deg`rees, pos or neg",
+				Usage:   "rotate the image by `deg`rees, pos or neg; [row] and [line] sort along this angle instead of rotating",
+			},
+			&cli.StringFlag{
+				Name:  "spiral_direction",
+				Value: "cw",
+				Usage: "which way the [spiral] winds [cw, ccw]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "cw" && v != "ccw" {
+						return fmt.Errorf("invalid spiral_direction \"%s\" [cw, ccw]", v)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "spiral_start",
+				Value: "tl",
+				Usage: "`corner` the [spiral] starts from [tl, tr, br, bl]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if !slices.Contains([]string{"tl", "tr", "br", "bl"}, v) {
+						return fmt.Errorf("invalid spiral_start \"%s\" [tl, tr, br, bl]", v)
+					}
+					return nil
+				},
+			},
+			&cli.BoolFlag{
+				Name:  "spiral_continuous",
+				Value: false,
+				Usage: "sort the whole [spiral] as one seam instead of ring by ring",
+			},
+			&cli.BoolFlag{
+				Name:  "spiral_outward",
+				Value: false,
+				Usage: "walk the [spiral] from the center out",
+			},
+			&cli.FloatFlag{
+				Name:  "center_x",
+				Value: 0.5,
+				Usage: "horizontal center of [rings], [rays] and [polar], as a `frac`tion of the width",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 || v > 1.0 {
+						return fmt.Errorf("center_x is outside of range [0.0-1.0]")
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "center_y",
+				Value: 0.5,
+				Usage: "vertical center of [rings], [rays] and [polar], as a `frac`tion of the height",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 || v > 1.0 {
+						return fmt.Errorf("center_y is outside of range [0.0-1.0]")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "polar_scan",
+				Value: "angular",
+				Usage: "which [polar] scanlines to sort [angular, radial]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "angular" && v != "radial" {
+						return fmt.Errorf("invalid polar_scan \"%s\" [angular, radial]", v)
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "polar_angles",
+				Value: 0,
+				Usage: "how many `N` slices [polar] cuts the circle into, 0 for one per pixel around the edge",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 0 {
+						return fmt.Errorf("polar_angles can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "polar_radius_step",
+				Value: 1,
+				Usage: "how many `px` apart [polar] rings are",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 1.0 {
+						return fmt.Errorf("polar_radius_step has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "seams",
+				Value: 100,
+				Usage: "`N` seams for [carve] and [carveh] (or [seamh]) to cut out, 0 carves the whole image (slow on big images). energy is only measured once, up front",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 0 {
+						return fmt.Errorf("seams can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.BoolFlag{
+				Name:  "flow_gradient",
+				Value: false,
+				Usage: "make [flow] run up the image gradient instead of along contours",
+			},
+			&cli.FloatFlag{
+				Name:  "wave_amplitude",
+				Value: 20,
+				Usage: "how many `px` [wavy] rows swing up and down",
+			},
+			&cli.FloatFlag{
+				Name:  "wave_frequency",
+				Value: 0.01,
+				Usage: "how often [wavy] rows swing, in noise `cells` per pixel",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 {
+						return fmt.Errorf("wave_frequency can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "cells",
+				Value: 64,
+				Usage: "roughly how many `N` regions [voronoi] and [superpixel] split the image into",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 1 {
+						return fmt.Errorf("cells has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "region_scan",
+				Value: "row",
+				Usage: "how to walk each [voronoi] and [superpixel] region [row, spiral]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "row" && v != "spiral" {
+						return fmt.Errorf("invalid region_scan \"%s\" [row, spiral]", v)
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "tile_cols",
+				Value: 8,
+				Usage: "how many `N` columns of tiles [tiles] cuts the image into",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 1 {
+						return fmt.Errorf("tile_cols has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "tile_rows",
+				Value: 8,
+				Usage: "how many `N` rows of tiles [tiles] cuts the image into",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 1 {
+						return fmt.Errorf("tile_rows has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "tile_mode",
+				Value: "random",
+				Usage: "how each of the [tiles] picks between rows, columns and spirals [random, checkerboard]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "random" && v != "checkerboard" {
+						return fmt.Errorf("invalid tile_mode \"%s\" [random, checkerboard]", v)
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "quad_variance",
+				Value: 400,
+				Usage: "[quadtree] blocks with a comparator `variance` under this stop splitting",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 {
+						return fmt.Errorf("quad_variance can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "quad_min_size",
+				Value: 4,
+				Usage: "[quadtree] blocks never split smaller than `px`",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 1 {
+						return fmt.Errorf("quad_min_size has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "contour_source",
+				Value: "lightness",
+				Usage: "what [contour] draws contour lines on [lightness, edges]",
+				Action: func(_ context.Context, _ *cli.Command, v string) error {
+					if v != "lightness" && v != "edges" {
+						return fmt.Errorf("invalid contour_source \"%s\" [lightness, edges]", v)
+					}
+					return nil
+				},
+			},
+			&cli.FloatFlag{
+				Name:  "contour_level",
+				Value: 0.5,
+				Usage: "`level` [contour] lines are drawn at",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 || v > 1.0 {
+						return fmt.Errorf("contour_level is outside of range [0.0-1.0]")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "contour_width",
+				Value: 3,
+				Usage: "how many `px` thick each [contour] band is",
+				Action: func(_ context.Context, _ *cli.Command, v int64) error {
+					if v < 1 {
+						return fmt.Errorf("contour_width has to be at least 1")
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:  "path_file",
+				Usage: "json (`file`.json, [[[x, y], ...], ...]) or svg polylines for [path] to follow, in pixels. cant be used with --angle",
+			},
+			&cli.FloatFlag{
+				Name:  "stroke_width",
+				Value: 9,
+				Usage: "how many `px` wide each [path] stroke is",
+				Action: func(_ context.Context, _ *cli.Command, v float64) error {
+					if v < 0.0 {
+						return fmt.Errorf("stroke_width can't be negative")
+					}
+					return nil
+				},
+			},
+			&cli.IntFlag{
+				Name:  "seed",
+				Value: 0,
+				Usage: "`seed` for random patterns and intervals, the same seed redoes the same sort (each image mixes in its file name, so it doesn't matter what else is in the input). 0 picks one at random",
 			},
 			&cli.IntFlag{
 				Name:    "section_length",
@@ -181,11 +510,77 @@ func main() {
 			shared.Config.Comparator = ctx.String("comparator")
 			shared.Config.Thresholds.Lower = float32(ctx.Float("lower_threshold"))
 			shared.Config.Thresholds.Upper = float32(ctx.Float("upper_threshold"))
+			shared.Config.EdgeThreshold = float32(ctx.Float("edge_threshold"))
+			shared.Config.Periodic.Amplitude = ctx.Float("period_amplitude")
+			shared.Config.Periodic.Period = ctx.Float("period_length")
+			shared.Config.Periodic.Phase = ctx.Float("period_phase")
+			shared.Config.Passes = int(ctx.Int("passes"))
+			shared.Config.ColorDistance = ctx.Float("color_distance")
+			shared.Config.DistanceMetric = ctx.String("distance_metric")
+			/// the metrics dont share a scale, so neither can their default
+			if shared.Config.ColorDistance == 0 {
+				shared.Config.ColorDistance = 40
+				if shared.Config.DistanceMetric == "deltae" {
+					shared.Config.ColorDistance = 10
+				}
+			}
 			shared.Config.SectionLength = int(ctx.Int("section_length"))
+			shared.Config.Polar.Scan = ctx.String("polar_scan")
+			shared.Config.Polar.Angles = int(ctx.Int("polar_angles"))
+			shared.Config.Polar.RadiusStep = ctx.Float("polar_radius_step")
+			shared.Config.SeamCount = int(ctx.Int("seams"))
+			shared.Config.FlowGradient = ctx.Bool("flow_gradient")
+			shared.Config.WaveAmplitude = ctx.Float("wave_amplitude")
+			shared.Config.WaveFrequency = ctx.Float("wave_frequency")
+			shared.Config.Seed = ctx.Int("seed")
+			shared.Config.Cells = int(ctx.Int("cells"))
+			shared.Config.RegionScan = ctx.String("region_scan")
+			shared.Config.Tiles.X = int(ctx.Int("tile_cols"))
+			shared.Config.Tiles.Y = int(ctx.Int("tile_rows"))
+			shared.Config.TileMode = ctx.String("tile_mode")
+			shared.Config.Quadtree.Variance = ctx.Float("quad_variance")
+			shared.Config.Quadtree.MinSize = int(ctx.Int("quad_min_size"))
+			shared.Config.Contour.Source = ctx.String("contour_source")
+			shared.Config.Contour.Level = float32(ctx.Float("contour_level"))
+			shared.Config.Contour.Width = int(ctx.Int("contour_width"))
+			shared.Config.StrokeWidth = ctx.Float("stroke_width")
+			threadCount := int(ctx.Int("threads"))
+
+			/// load the polylines once, every image follows the same ones
+			if shared.Config.Pattern == "path" {
+				pathFile := ctx.String("path_file")
+				if pathFile == "" {
+					return cli.Exit("The path pattern needs a --path_file", 1)
+				}
+				/// the image would turn under the path, draw it at the angle you want instead
+				if math.Mod(ctx.Float("angle"), 360) != 0 {
+					return cli.Exit("The path pattern can't be used with --angle", 1)
+				}
+				polylines, err := patterns.ReadPolylines(resolvePath(pathFile))
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Path file %q could not be read: %s", pathFile, err), 1)
+				}
+				shared.Config.Polylines = polylines
+			}
+			/// pick one now so it gets printed with the rest of the config
+			if shared.Config.Seed == 0 {
+				shared.Config.Seed = time.Now().UnixNano()
+			}
 			shared.Config.Reverse = ctx.Bool("reverse")
+			shared.Config.MaskBlend = ctx.Bool("mask_blend")
 			shared.Config.Randomness = float32(ctx.Float("randomness"))
 			shared.Config.Angle = ctx.Float("angle")
-			threadCount := int(ctx.Int("threads"))
+			shared.Config.Spiral.Direction = ctx.String("spiral_direction")
+			shared.Config.Spiral.Start = ctx.String("spiral_start")
+			shared.Config.Spiral.Continuous = ctx.Bool("spiral_continuous")
+			shared.Config.Spiral.Outward = ctx.Bool("spiral_outward")
+			shared.Config.Center.X = float32(ctx.Float("center_x"))
+			shared.Config.Center.Y = float32(ctx.Float("center_y"))
+
+			/// an angled row is just a line, and lines dont need the image rotated
+			if shared.Config.Pattern == "row" && math.Mod(shared.Config.Angle, 360) != 0 {
+				shared.Config.Pattern = "line"
+			}
 
 			/// profiling
 			if ctx.Bool("profile") {
@@ -229,6 +624,24 @@ func main() {
 				}
 			}
 
+			/// interval image, decoded once and shared by every image
+			var intervalImg image.Image
+			if shared.Config.Interval == "file" {
+				intervalPath := ctx.String("interval_image")
+				if intervalPath == "" {
+					return cli.Exit("The file interval needs an --interval_image", 1)
+				}
+				intervalFile, err := os.Open(resolvePath(intervalPath))
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Interval image %q could not be opened", intervalPath), 1)
+				}
+				defer intervalFile.Close()
+				intervalImg, _, err = image.Decode(intervalFile)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Interval image %q could not be decoded: %s", intervalPath, err), 1)
+				}
+			}
+
 			/// masking
 			if mask != "" {
 				maskfile, err := os.Open(mask)
@@ -292,7 +705,7 @@ func main() {
 					}
 
 					fmt.Println(fmt.Sprintf("Loading image %d (%s -> %s)...", i+1, in, out))
-					err := sortingTime(in, out, mask)
+					err := sortingTime(in, out, mask, intervalImg)
 					if err != nil {
 						println(fmt.Errorf("Error occured during sort of image %d (%q): %q", i+1, in, err))
 					}
@@ -343,7 +756,7 @@ func resolvePath(path string) string {
 	return path
 }
 
-func sortingTime(input, output, maskpath string) error {
+func sortingTime(input, output, maskpath string, intervalImg image.Image) error {
 	file, err := os.Open(input)
 	if err != nil {
 		return cli.Exit(fmt.Sprintf("Input %q could not be opened: %s", input, err), 1)
@@ -362,7 +775,9 @@ func sortingTime(input, output, maskpath string) error {
 	/// this is used in the writing step cause `imaging` doesnt have a option to
 	/// auto-crop transparency
 	originalDims := rawImg.Bounds()
-	if math.Mod(shared.Config.Angle, 360) != 0 {
+	/// lines walk the angle themselves
+	rotate := shared.Config.Pattern != "line" && math.Mod(shared.Config.Angle, 360) != 0
+	if rotate {
 		rawImg = (*image.RGBA)(imaging.Rotate(rawImg, shared.Config.Angle, color.Transparent))
 	}
 
@@ -388,7 +803,7 @@ func sortingTime(input, output, maskpath string) error {
 		}
 
 		/// RO TA TE (again)
-		if math.Mod(shared.Config.Angle, 360) != 0 {
+		if rotate {
 			rawMask = imaging.Rotate(rawMask, float64(shared.Config.Angle), color.Transparent)
 		}
 
@@ -396,13 +811,48 @@ func sortingTime(input, output, maskpath string) error {
 		rawMask = nil
 	}
 
+	/// sort everything, the mask comes back in when blending
+	/// savers write over img, so hang onto the original
+	loaderMask := mask
+	var original *image.RGBA
+	if shared.Config.MaskBlend {
+		loaderMask = patterns.BlendLoadMask(mask)
+		original = image.NewRGBA(sortingDims)
+		copy(original.Pix, img.Pix)
+	}
+
 	/// load seams
 	loader := patterns.Loader[fmt.Sprintf("%sload", shared.Config.Pattern)]
 	if loader == nil {
 		fmt.Println("invalid pattern")
 		return cli.Exit("invalid pattern", 2)
 	}
-	seams, data := loader(img, mask)
+	/// seeded per image (and per seam), so the same seed sorts the same no matter the threads.
+	/// going by the file's name means a frame re-rendered on its own comes out the same too
+	imageSeed := intervals.ImageSeed(shared.Config.Seed, filepath.Base(input))
+	seams, data := loader(img, loaderMask, imageSeed)
+	state := intervals.NewState(imageSeed)
+	/// some intervals split on a per-pixel guide rather than the pixels themselves
+	if shared.Config.Interval == "edges" {
+		state.Guide = patterns.EdgeMagnitude(img).Pix
+	} else if shared.Config.Interval == "file" {
+		/// nearest neighbor keeps painted boundaries crisp
+		if intervalImg.Bounds().Size() != originalDims.Size() {
+			intervalImg = imaging.Resize(intervalImg, originalDims.Dx(), originalDims.Dy(), imaging.NearestNeighbor)
+		}
+		/// RO TA TE (yet again)
+		if rotate {
+			intervalImg = imaging.Rotate(intervalImg, shared.Config.Angle, color.Transparent)
+		}
+		guide := image.NewGray(sortingDims)
+		draw.Draw(guide, guide.Bounds(), intervalImg, intervalImg.Bounds().Min, draw.Src)
+		state.Guide = guide.Pix
+	}
+	/// and need to know where each seam pixel is to look it up
+	var paths [][]int
+	if state.Guide != nil {
+		paths = patterns.SeamPaths(shared.Config.Pattern, seams, img.Bounds(), data)
+	}
 	/// more whitespace
 	/// im not gonna rant again
 	/// just
@@ -411,8 +861,12 @@ func sortingTime(input, output, maskpath string) error {
 	println(fmt.Sprintf("Sorting %s...", input))
 	/// pass the rows to the sorter
 	start := time.Now()
-	for _, seam := range *seams {
-		intervals.Sort(seam)
+	for i, seam := range *seams {
+		state.SetSeam(i)
+		if paths != nil {
+			state.Path = paths[i]
+		}
+		intervals.Sort(seam, state)
 	}
 	end := time.Now()
 	elapsed := end.Sub(start)
@@ -424,9 +878,12 @@ func sortingTime(input, output, maskpath string) error {
 	/// now write
 	outputImg := patterns.Saver[fmt.Sprintf("%ssave", shared.Config.Pattern)](img, seams, img.Bounds(), data)
 	// outputImg := patterns.Saver[fmt.Sprintf("%ssave", shared.Config.Pattern)](image.NewRGBA(sortingDims), stretches, img.Bounds(), data)
+	if shared.Config.MaskBlend {
+		patterns.BlendMask(outputImg, original, mask)
+	}
 
 	/// ET AT OR
-	if math.Mod(shared.Config.Angle, 360) != 0 {
+	if rotate {
 		outputImg = (*image.RGBA)(imaging.Rotate(outputImg, -shared.Config.Angle, color.Transparent))
 		/// gotta crop the invisible pixels
 		if math.Mod(shared.Config.Angle, 90) != 0 {
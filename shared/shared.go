@@ -20,6 +20,51 @@ var Config struct {
 	Reverse bool
 	// pixels outside of these arent sorted
 	Thresholds types.ThresholdConfig
-	// rotate image
+	// sort everything, then fade the original back in by the mask
+	MaskBlend bool
+	// edge strength (0-1) the edges interval splits at
+	EdgeThreshold float32
+	// how the sine, triangle and sawtooth intervals swing
+	Periodic types.PeriodicConfig
+	// how many rounds of swaps the partial interval does
+	Passes int
+	// how far apart neighbors have to be for the distance interval to split them,
+	// on DistanceMetric's scale (rgb 0-441, deltae 0-100)
+	ColorDistance float64
+	// rgb or deltae
+	DistanceMetric string
+	// rotate image, or the direction of the line pattern
 	Angle float64
+	// how the spiral pattern winds
+	Spiral types.SpiralConfig
+	// origin of the rings, rays and polar patterns
+	Center types.CenterConfig
+	// polar grid the polar pattern unwraps into
+	Polar types.PolarConfig
+	// how many seams the carve and carveh (seamh) patterns remove, 0 for all of them
+	SeamCount int
+	// flow pattern follows the gradient instead of the contours
+	FlowGradient bool
+	// how far (in pixels) wavy rows swing up and down
+	WaveAmplitude float64
+	// how often wavy rows swing, in noise cells per pixel
+	WaveFrequency float64
+	// seeds everything random
+	Seed int64
+	// roughly how many regions voronoi and superpixel split into
+	Cells int
+	// how regions get walked, row or spiral
+	RegionScan string
+	// grid the tiles pattern cuts the image into
+	Tiles types.GridConfig
+	// how tiles pick their direction, random or checkerboard
+	TileMode string
+	// when the quadtree pattern stops splitting
+	Quadtree types.QuadtreeConfig
+	// what the contour pattern traces
+	Contour types.ContourConfig
+	// lines for the path pattern to follow, read from --path_file
+	Polylines []types.Polyline
+	// how thick (in pixels) path strokes are
+	StrokeWidth float64
 }
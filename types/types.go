@@ -1,6 +1,9 @@
 package types
 
-import "image/color"
+import (
+	"fmt"
+	"image/color"
+)
 
 type PixelWithMask struct {
 	R, G, B, A uint8
@@ -34,6 +37,69 @@ type ThresholdConfig struct {
 	Lower, Upper float32
 }
 
+type SpiralConfig struct {
+	// cw or ccw
+	Direction string
+	// corner to start from, tl, tr, br or bl
+	Start string
+	// all rings in one seam
+	Continuous bool
+	// start from the middle
+	Outward bool
+}
+
+type ContourConfig struct {
+	// lightness or edges
+	Source string
+	// where the contour lines get drawn, 0-1
+	Level float32
+	// how many pixels thick each band is
+	Width int
+}
+
+type PolarConfig struct {
+	// angular or radial scanlines
+	Scan string
+	// how many slices the circle is cut into, 0 for one per pixel around the edge
+	Angles int
+	// how many pixels apart rings are
+	RadiusStep float64
+}
+
+type PeriodicConfig struct {
+	// how far stretch lengths swing, as a fraction of the section length
+	Amplitude float64
+	// how many pixels one swing takes
+	Period float64
+	// how many pixels each seam's swing is shifted from the last
+	Phase float64
+}
+
+type QuadtreeConfig struct {
+	// blocks flatter than this stop splitting
+	Variance float64
+	// blocks smaller than this (in pixels) stop splitting
+	MinSize int
+}
+
+// x, y points in pixels
+type Polyline [][2]float64
+
+// keeps printed configs short
+func (polyline Polyline) String() string {
+	return fmt.Sprintf("polyline(%d points)", len(polyline))
+}
+
+// columns and rows
+type GridConfig struct {
+	X, Y int
+}
+
+// fractions of the image width and height
+type CenterConfig struct {
+	X, Y float32
+}
+
 type ComparatorFunc func(a, b PixelWithMask) int
 
 type SorterFunc func(interval []PixelWithMask)